@@ -0,0 +1,57 @@
+package cloning
+
+import (
+	"reflect"
+)
+
+// Alias is a pointer, slice or map that is referenced from more than one place in a cloned instance.
+// Path is where it was first seen and AliasPath is where it was referenced again, both in a property id style.
+type Alias struct {
+	Kind      reflect.Kind
+	Path      string
+	AliasPath string
+}
+
+func (this *Alias) String() string {
+	return this.Kind.String() + " " + this.AliasPath + " -> " + this.Path
+}
+
+// aliasKey is the address and the type of a pointer, map or slice backing array,
+// as a struct and its first field have the same address.
+type aliasKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+type aliasEntry struct {
+	clone reflect.Value
+	path  *equalPath
+	//source and cloned are the first slice seen of a backing array and the number of its slots that were cloned
+	source reflect.Value
+	cloned int
+}
+
+// cloneState tracks the already cloned values of a single clone, so cycles and aliases are kept in the clone.
+type cloneState struct {
+	seen    map[aliasKey]*aliasEntry
+	report  bool
+	aliases []*Alias
+	//aliased is true when the slices and maps are tracked too, not only the pointers
+	aliased bool
+	//hooked is true when the cloner has hooks, so the values are cloned one by one to match them
+	hooked bool
+}
+
+func (this *cloneState) see(key aliasKey, entry *aliasEntry) {
+	if this.seen == nil {
+		this.seen = make(map[aliasKey]*aliasEntry)
+	}
+	this.seen[key] = entry
+}
+
+func (this *cloneState) alias(entry *aliasEntry, path *equalPath, kind reflect.Kind) {
+	if !this.report {
+		return
+	}
+	this.aliases = append(this.aliases, &Alias{Kind: kind, Path: entry.path.id, AliasPath: path.id})
+}
@@ -0,0 +1,185 @@
+package cloning
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+type HookAction int
+
+const (
+	//HookDrop leaves the struct field or slice element empty and removes the map entry
+	HookDrop HookAction = iota + 1
+	//HookMask sets strings to MaskedValue and any other value to its zero value
+	HookMask
+	//HookReplace sets the result of the Replace function
+	HookReplace
+	//HookShare keeps the value itself, it is shared by the clone instead of deep copied
+	HookShare
+)
+
+const MaskedValue = "*****"
+
+// CloneHook transforms a value while it is cloned, instead of cloning it.
+type CloneHook struct {
+	Action  HookAction
+	Replace func(value interface{}) interface{}
+}
+
+type hookEntry struct {
+	pattern  string
+	segments []string
+	hook     *CloneHook
+}
+
+// AddHook applies the hook to the values whose property id, or node path without the keys, matches the pattern.
+// Each of the pattern dot separated segments is matched as in path.Match to the same segment of the id,
+// so a '*' does not span a dot, e.g. "device.*.password" matches "device.credentials<admin>.password" only.
+func (this *Cloner) AddHook(pattern string, hook *CloneHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+	if hook.Action == HookReplace && hook.Replace == nil {
+		return errors.New("replace hook for " + pattern + " has no Replace function")
+	}
+	segments := idSegments(pattern)
+	for _, segment := range segments {
+		_, err := path.Match(segment, "")
+		if err != nil {
+			return errors.New("invalid hook pattern " + pattern + ": " + err.Error())
+		}
+	}
+	this.hooks = append(this.hooks, &hookEntry{pattern: pattern, segments: segments, hook: hook})
+	this.resetPlans()
+	return nil
+}
+
+// AddSensitiveHooks applies the hook to every node under, and including, the given node that is sensitive.
+func (this *Cloner) AddSensitiveHooks(node *l8reflect.L8Node, hook *CloneHook) error {
+	if node == nil {
+		return nil
+	}
+	if helping.IsSensitive(node) {
+		err := this.AddHook(helping.NodeCacheKey(node), hook)
+		if err != nil {
+			return err
+		}
+	}
+	for _, attr := range node.Attributes {
+		err := this.AddSensitiveHooks(attr, hook)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloneAt clones any as the value at the property id and node path, so the hooks match the values in it.
+// The value itself is transformed if a hook matches it, or any of its parents.
+func (this *Cloner) CloneAt(any interface{}, id, nodePath string) interface{} {
+	if any == nil {
+		return nil
+	}
+	value := reflect.ValueOf(any)
+	vpath := &equalPath{id: id, node: nodePath}
+	for vpath != nil {
+		hooked, ok := this.applyHook(value, vpath)
+		if ok {
+			if !hooked.IsValid() {
+				return nil
+			}
+			return hooked.Interface()
+		}
+		vpath = vpath.parent()
+	}
+	state := &cloneState{aliased: this.keepAliases, hooked: len(this.hooks) > 0}
+	valueClone := this.clone(value, state, &equalPath{id: id, node: nodePath})
+	if !valueClone.IsValid() {
+		return nil
+	}
+	return valueClone.Interface()
+}
+
+// applyHook returns the value the hook matching the path transforms the value into, and false if no hook matches.
+// An invalid value means the value is dropped.
+func (this *Cloner) applyHook(value reflect.Value, vpath *equalPath) (reflect.Value, bool) {
+	for _, entry := range this.hooks {
+		if matchSegments(entry.segments, vpath.id) || matchSegments(entry.segments, vpath.node) {
+			return entry.hook.apply(value), true
+		}
+	}
+	return value, false
+}
+
+func matchSegments(segments []string, id string) bool {
+	idSegments := idSegments(id)
+	if len(idSegments) != len(segments) {
+		return false
+	}
+	for i, segment := range segments {
+		matched, _ := path.Match(segment, idSegments[i])
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// idSegments splits a property id, or a pattern, at its dots that are not in a key.
+func idSegments(id string) []string {
+	segments := make([]string, 0, 4)
+	start := 0
+	for i := 0; i < len(id); i++ {
+		switch id[i] {
+		case '<':
+			end := helping.KeyEnd(id, i)
+			if end == -1 {
+				return append(segments, id[start:])
+			}
+			i = end
+		case '.':
+			segments = append(segments, id[start:i])
+			start = i + 1
+		}
+	}
+	return append(segments, id[start:])
+}
+
+// parent is the node path of the parent node, or nil for a root.
+func (this *equalPath) parent() *equalPath {
+	index := strings.LastIndex(this.node, ".")
+	if index == -1 {
+		return nil
+	}
+	return &equalPath{id: this.node[:index], node: this.node[:index]}
+}
+
+func (this *CloneHook) apply(value reflect.Value) reflect.Value {
+	switch this.Action {
+	case HookMask:
+		if value.Kind() == reflect.String {
+			return reflect.ValueOf(MaskedValue).Convert(value.Type())
+		}
+		return reflect.Zero(value.Type())
+	case HookReplace:
+		replaced := reflect.ValueOf(this.Replace(value.Interface()))
+		if !replaced.IsValid() {
+			return reflect.Zero(value.Type())
+		}
+		if replaced.Type().AssignableTo(value.Type()) {
+			return replaced
+		}
+		if replaced.Type().ConvertibleTo(value.Type()) {
+			return replaced.Convert(value.Type())
+		}
+		return reflect.Zero(value.Type())
+	case HookShare:
+		return value
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,125 @@
+package cloning
+
+import (
+	"reflect"
+)
+
+type cloneFunc func(*Cloner, *clonePlan, reflect.Value, *cloneState, *equalPath) reflect.Value
+
+// clonePlan is how a type is cloned, compiled once per type so cloning a value does not
+// dispatch on its kind or check its field names again.
+type clonePlan struct {
+	typ   reflect.Type
+	clone cloneFunc
+	//pointerFree types hold no references, so they are cloned by copying their memory
+	pointerFree bool
+	//elem is the plan of the pointer, slice, array or map elements
+	elem *clonePlan
+	//fields are the struct fields to clone, without the skipped ones
+	fields []planField
+	//skipped are the indexes of the skipped struct fields, left empty by CopyInto
+	skipped    []int
+	shared     bool
+	typeCloner TypeCloner
+}
+
+type planField struct {
+	index int
+	name  string
+	plan  *clonePlan
+}
+
+func (this *Cloner) planOf(typ reflect.Type) *clonePlan {
+	this.plansLock.RLock()
+	plan, ok := this.plans[typ]
+	this.plansLock.RUnlock()
+	if ok {
+		return plan
+	}
+	this.plansLock.Lock()
+	defer this.plansLock.Unlock()
+	return this.buildPlan(typ)
+}
+
+func (this *Cloner) resetPlans() {
+	this.plansLock.Lock()
+	defer this.plansLock.Unlock()
+	this.plans = make(map[reflect.Type]*clonePlan)
+}
+
+// buildPlan must be called with the plans lock held. The plan is cached before its elements are
+// built, so a recursive type refers to its own plan.
+func (this *Cloner) buildPlan(typ reflect.Type) *clonePlan {
+	plan, ok := this.plans[typ]
+	if ok {
+		return plan
+	}
+	plan = &clonePlan{typ: typ}
+	this.plans[typ] = plan
+
+	if this.typeCloners != nil {
+		typeCloner, ok := this.typeCloners[typ]
+		if ok {
+			plan.typeCloner = typeCloner
+			plan.clone = typeClone
+			return plan
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		plan.clone = scalarClone
+		plan.pointerFree = true
+	case reflect.Func:
+		// Functions can't be truly cloned in Go, so the same function is returned
+		plan.clone = scalarClone
+	case reflect.Chan:
+		plan.clone = chanClone
+	case reflect.Interface:
+		plan.clone = interfaceClone
+	case reflect.Ptr:
+		plan.clone = ptrClone
+		plan.shared = this.sharedTypes != nil && this.sharedTypes[typ.Elem()]
+		plan.elem = this.buildPlan(typ.Elem())
+	case reflect.Slice:
+		plan.clone = sliceClone
+		plan.elem = this.buildPlan(typ.Elem())
+	case reflect.Map:
+		plan.clone = mapClone
+		plan.elem = this.buildPlan(typ.Elem())
+	case reflect.Array:
+		plan.elem = this.buildPlan(typ.Elem())
+		//with hooks, the elements are cloned one by one to match them
+		plan.pointerFree = plan.elem.pointerFree && len(this.hooks) == 0
+		if plan.pointerFree {
+			plan.clone = scalarClone
+		} else {
+			plan.clone = arrayClone
+		}
+	case reflect.Struct:
+		//a struct with skipped fields is not copied as a whole, as the skipped fields are left empty,
+		//nor when there are hooks, as its fields may match them
+		plan.pointerFree = len(this.hooks) == 0
+		for i := 0; i < typ.NumField(); i++ {
+			if SkipFieldByName(typ.Field(i).Name) {
+				plan.pointerFree = false
+				plan.skipped = append(plan.skipped, i)
+				continue
+			}
+			field := planField{index: i, name: typ.Field(i).Name, plan: this.buildPlan(typ.Field(i).Type)}
+			plan.pointerFree = plan.pointerFree && field.plan.pointerFree
+			plan.fields = append(plan.fields, field)
+		}
+		if plan.pointerFree {
+			plan.clone = scalarClone
+		} else {
+			plan.clone = structClone
+		}
+	default:
+		plan.clone = noClone
+	}
+	return plan
+}
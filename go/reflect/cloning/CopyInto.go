@@ -0,0 +1,264 @@
+package cloning
+
+import (
+	"errors"
+	"reflect"
+)
+
+type copyState struct {
+	//refs maps the source pointers to their destination pointers, as in Clone, and marks the destination pointers,
+	//slices and maps that were already copied into, so they are not reused twice
+	refs map[copyKey]reflect.Value
+	//hooked is true when the cloner has hooks, so the values are copied one by one to match them
+	hooked bool
+}
+
+// copyKey is a source pointer, or a destination pointer, slice or map when used is true.
+type copyKey struct {
+	aliasKey
+	used bool
+}
+
+// CopyInto deep copies src into dst, reusing the existing pointers, slices with enough capacity and maps of dst
+// instead of allocating a new graph. dst must be a non nil pointer to the type of src, or to the type src points to.
+// A pointer, slice or map of dst referenced from more than one place is reused only once.
+// The shared types and the hooks apply as in Clone.
+func (this *Cloner) CopyInto(dst, src interface{}) error {
+	dstValue := reflect.ValueOf(dst)
+	if !dstValue.IsValid() || dstValue.Kind() != reflect.Ptr || dstValue.IsNil() {
+		return errors.New("copy destination must be a non nil pointer")
+	}
+	srcValue := reflect.ValueOf(src)
+	if !srcValue.IsValid() {
+		return errors.New("copy source is nil")
+	}
+	if srcValue.Type() == dstValue.Type() {
+		if srcValue.IsNil() {
+			return errors.New("copy source is nil")
+		}
+		if srcValue.Pointer() == dstValue.Pointer() {
+			return nil
+		}
+		srcValue = srcValue.Elem()
+	}
+	if srcValue.Type() != dstValue.Type().Elem() {
+		return errors.New("cannot copy " + srcValue.Type().String() + " into " + dstValue.Type().String())
+	}
+	//the state maps are allocated on the first pointer, slice or map
+	state := &copyState{hooked: len(this.hooks) > 0}
+	var path *equalPath
+	if state.hooked {
+		path = rootPath(srcValue)
+	}
+	if reflect.ValueOf(src).Kind() == reflect.Ptr {
+		state.see(aliasKey{addr: reflect.ValueOf(src).Pointer(), typ: dstValue.Type()}, dstValue)
+	}
+	state.use(aliasKey{addr: dstValue.Pointer(), typ: dstValue.Type()})
+	this.copyInto(dstValue.Elem(), srcValue, this.planOf(srcValue.Type()), state, path)
+	return nil
+}
+
+func (this *Cloner) copyInto(dst, src reflect.Value, plan *clonePlan, state *copyState, path *equalPath) {
+	//type cloners, pointer free values and the other kinds do not use the clone state
+	if plan.typeCloner != nil || plan.pointerFree {
+		dst.Set(plan.clone(this, plan, src, nil, path))
+		return
+	}
+	switch plan.typ.Kind() {
+	case reflect.Ptr:
+		this.ptrCopy(dst, src, plan, state, path)
+	case reflect.Struct:
+		//skipped fields are left empty, as in Clone
+		for _, index := range plan.skipped {
+			if dst.Field(index).CanSet() {
+				dst.Field(index).Set(reflect.Zero(dst.Field(index).Type()))
+			}
+		}
+		for _, field := range plan.fields {
+			if !dst.Field(field.index).CanSet() {
+				continue
+			}
+			fieldPath := path.field(field.name)
+			if this.copyHooked(dst.Field(field.index), src.Field(field.index), state, fieldPath) {
+				continue
+			}
+			this.copyInto(dst.Field(field.index), src.Field(field.index), field.plan, state, fieldPath)
+		}
+	case reflect.Slice:
+		this.sliceCopy(dst, src, plan, state, path)
+	case reflect.Map:
+		this.mapCopy(dst, src, plan, state, path)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			if this.copyHooked(dst.Index(i), src.Index(i), state, path.key(i)) {
+				continue
+			}
+			this.copyInto(dst.Index(i), src.Index(i), plan.elem, state, path.key(i))
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		if !dst.IsNil() && dst.Elem().Type() == src.Elem().Type() {
+			elem.Set(dst.Elem())
+		}
+		this.copyInto(elem, src.Elem(), this.planOf(src.Elem().Type()), state, path)
+		dst.Set(elem)
+	default:
+		dst.Set(plan.clone(this, plan, src, nil, path))
+	}
+}
+
+// copyHooked sets the value a hook matching the path transforms src into, returning false if no hook matches.
+func (this *Cloner) copyHooked(dst, src reflect.Value, state *copyState, path *equalPath) bool {
+	if !state.hooked {
+		return false
+	}
+	hooked, ok := this.applyHook(src, path)
+	if !ok {
+		return false
+	}
+	if hooked.IsValid() {
+		dst.Set(hooked)
+	} else {
+		dst.Set(reflect.Zero(dst.Type()))
+	}
+	return true
+}
+
+func (state *copyState) see(key aliasKey, dst reflect.Value) {
+	if state.refs == nil {
+		state.refs = make(map[copyKey]reflect.Value)
+	}
+	state.refs[copyKey{aliasKey: key}] = dst
+}
+
+func (state *copyState) use(key aliasKey) {
+	if state.refs == nil {
+		state.refs = make(map[copyKey]reflect.Value)
+	}
+	state.refs[copyKey{aliasKey: key, used: true}] = reflect.Value{}
+}
+
+// reuse returns true if the destination pointer, slice or map was not copied into yet, marking it as used.
+func (state *copyState) reuse(dst, src reflect.Value) bool {
+	if dst.IsNil() || dst.Pointer() == src.Pointer() {
+		return false
+	}
+	key := aliasKey{addr: dst.Pointer(), typ: dst.Type()}
+	if dst.Kind() == reflect.Slice {
+		//the slices of the same backing array end at the same address, as in Clone
+		key.addr += uintptr(dst.Cap()) * dst.Type().Elem().Size()
+	}
+	if _, ok := state.refs[copyKey{aliasKey: key, used: true}]; ok {
+		return false
+	}
+	state.use(key)
+	return true
+}
+
+func (this *Cloner) ptrCopy(dst, src reflect.Value, plan *clonePlan, state *copyState, path *equalPath) {
+	if src.IsNil() || plan.shared {
+		dst.Set(src)
+		return
+	}
+	key := aliasKey{addr: src.Pointer(), typ: src.Type()}
+	exist, ok := state.refs[copyKey{aliasKey: key}]
+	if ok {
+		dst.Set(exist)
+		return
+	}
+	if !state.reuse(dst, src) {
+		dst.Set(reflect.New(src.Elem().Type()))
+		state.use(aliasKey{addr: dst.Pointer(), typ: dst.Type()})
+	}
+	//the pointer itself is kept, as dst may be the temporary value of a map entry
+	state.see(key, dst.Elem().Addr())
+	this.copyInto(dst.Elem(), src.Elem(), plan.elem, state, path)
+}
+
+func (this *Cloner) sliceCopy(dst, src reflect.Value, plan *clonePlan, state *copyState, path *equalPath) {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return
+	}
+	target := dst
+	if dst.Cap() < src.Len() || !state.reuse(dst, src) {
+		target = reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+	} else {
+		target = dst.Slice(0, src.Len())
+	}
+	if plan.elem.pointerFree && !state.hooked {
+		reflect.Copy(target, src)
+	} else {
+		for i := 0; i < src.Len(); i++ {
+			var elemPath *equalPath
+			if state.hooked {
+				elemPath = path.key(i)
+				if this.copyHooked(target.Index(i), src.Index(i), state, elemPath) {
+					continue
+				}
+			}
+			this.copyInto(target.Index(i), src.Index(i), plan.elem, state, elemPath)
+		}
+	}
+	dst.Set(target)
+}
+
+// mapCopy copies the entries of src into the map of dst, iterating with a single key and value that are
+// reused for all the entries, as the map values are not addressable.
+func (this *Cloner) mapCopy(dst, src reflect.Value, plan *clonePlan, state *copyState, path *equalPath) {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return
+	}
+	if !state.reuse(dst, src) {
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+	}
+	key := reflect.New(src.Type().Key()).Elem()
+	srcElem := reflect.New(src.Type().Elem()).Elem()
+	//a pointer free value is set as is, other values are copied into the existing entry
+	var elem reflect.Value
+	if !plan.elem.pointerFree || state.hooked {
+		elem = reflect.New(src.Type().Elem()).Elem()
+	}
+	iter := src.MapRange()
+	for iter.Next() {
+		key.SetIterKey(iter)
+		srcElem.SetIterValue(iter)
+		var entryPath *equalPath
+		if state.hooked {
+			entryPath = path.key(key.Interface())
+			hooked, ok := this.applyHook(srcElem, entryPath)
+			if ok {
+				//a dropped entry is removed, as Clone leaves it out
+				dst.SetMapIndex(key, hooked)
+				continue
+			}
+		}
+		if !elem.IsValid() {
+			dst.SetMapIndex(key, srcElem)
+			continue
+		}
+		existing := dst.MapIndex(key)
+		if existing.IsValid() {
+			elem.Set(existing)
+		} else {
+			elem.Set(reflect.Zero(elem.Type()))
+		}
+		this.copyInto(elem, srcElem, plan.elem, state, entryPath)
+		dst.SetMapIndex(key, elem)
+	}
+	//the entries of dst that are not in src are removed
+	if dst.Len() > src.Len() {
+		iter = dst.MapRange()
+		for iter.Next() {
+			key.SetIterKey(iter)
+			if !src.MapIndex(key).IsValid() {
+				dst.SetMapIndex(key, reflect.Value{})
+			}
+		}
+	}
+}
@@ -2,258 +2,258 @@ package cloning
 
 import (
 	"reflect"
-	"strconv"
 	"strings"
+	"sync"
 )
 
 type Cloner struct {
-	cloners map[reflect.Kind]func(reflect.Value, string, map[string]reflect.Value) reflect.Value
+	sharedTypes map[reflect.Type]bool
+	typeCloners map[reflect.Type]TypeCloner
+	//plans are the compiled clone plans, built on the first sight of a type
+	plans     map[reflect.Type]*clonePlan
+	plansLock sync.RWMutex
+	hooks     []*hookEntry
+	//keepAliases makes Clone keep the slices and maps aliases, the pointers are always kept for the cycles
+	keepAliases bool
 }
 
 func NewCloner() *Cloner {
 	cloner := &Cloner{}
-	cloner.initCloners()
+	cloner.plans = make(map[reflect.Type]*clonePlan)
+	cloner.initTypeCloners()
 	return cloner
 }
 
-func (this *Cloner) initCloners() {
-	this.cloners = make(map[reflect.Kind]func(reflect.Value, string, map[string]reflect.Value) reflect.Value)
-	this.cloners[reflect.Int] = this.intCloner
-	this.cloners[reflect.Int8] = this.int8Cloner
-	this.cloners[reflect.Int16] = this.int16Cloner
-	this.cloners[reflect.Int32] = this.int32Cloner
-	this.cloners[reflect.Int64] = this.int64Cloner
-	this.cloners[reflect.Uint] = this.uintCloner
-	this.cloners[reflect.Uint8] = this.uint8Cloner
-	this.cloners[reflect.Uint16] = this.uint16Cloner
-	this.cloners[reflect.Uint32] = this.uint32Cloner
-	this.cloners[reflect.Uint64] = this.uint64Cloner
-	this.cloners[reflect.Float32] = this.float32Cloner
-	this.cloners[reflect.Float64] = this.float64Cloner
-	this.cloners[reflect.Complex64] = this.complex64Cloner
-	this.cloners[reflect.Complex128] = this.complex128Cloner
-	this.cloners[reflect.Bool] = this.boolCloner
-	this.cloners[reflect.String] = this.stringCloner
-	this.cloners[reflect.Array] = this.arrayCloner
-	this.cloners[reflect.Slice] = this.sliceCloner
-	this.cloners[reflect.Map] = this.mapCloner
-	this.cloners[reflect.Ptr] = this.ptrCloner
-	this.cloners[reflect.Struct] = this.structCloner
-	this.cloners[reflect.Interface] = this.interfaceCloner
-	this.cloners[reflect.Chan] = this.chanCloner
-	this.cloners[reflect.Func] = this.funcCloner
+// AddSharedType makes the cloner share, instead of deep copy, pointers to the given struct type.
+func (this *Cloner) AddSharedType(typ reflect.Type) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if this.sharedTypes == nil {
+		this.sharedTypes = make(map[reflect.Type]bool)
+	}
+	this.sharedTypes[typ] = true
+	this.resetPlans()
+}
+
+// KeepAliases makes the cloner keep the sharing of slices and maps in the clone, as it does for pointers.
+// It is off by default as tracking every slice and map costs allocations, CloneReportAliases always keeps them.
+func (this *Cloner) KeepAliases() {
+	this.keepAliases = true
 }
 
 func (this *Cloner) Clone(any interface{}) interface{} {
+	clone, _ := this.cloneRoot(any, false)
+	return clone
+}
+
+// CloneReportAliases clones like Clone and also returns the pointers, slices and maps
+// that are referenced from more than one place in the instance, including the cycles.
+func (this *Cloner) CloneReportAliases(any interface{}) (interface{}, []*Alias) {
+	return this.cloneRoot(any, true)
+}
+
+func (this *Cloner) cloneRoot(any interface{}, report bool) (interface{}, []*Alias) {
 	if any == nil {
-		return nil
+		return nil, nil
 	}
 	value := reflect.ValueOf(any)
-	stopLoop := make(map[string]reflect.Value)
-	valueClone := this.clone(value, "", stopLoop)
+	state := &cloneState{report: report, aliased: report || this.keepAliases, hooked: len(this.hooks) > 0}
+	var path *equalPath
+	if report || state.hooked {
+		path = rootPath(value)
+	}
+	valueClone := this.clone(value, state, path)
 	if !valueClone.IsValid() {
-		return nil
+		return nil, state.aliases
 	}
-	return valueClone.Interface()
+	return valueClone.Interface(), state.aliases
 }
 
-func (this *Cloner) clone(value reflect.Value, fieldName string, stopLoop map[string]reflect.Value) reflect.Value {
+func (this *Cloner) clone(value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
 	if !value.IsValid() {
 		return value
 	}
-	kind := value.Kind()
-	cloner := this.cloners[kind]
-	if cloner == nil {
-		panic("No cloner for kind:" + kind.String() + ":" + fieldName)
-	}
-	return cloner(value, fieldName, stopLoop)
+	plan := this.planOf(value.Type())
+	return plan.clone(this, plan, value, state, path)
 }
 
-func (this *Cloner) sliceCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
+// scalarClone returns the value itself, as setting it into the clone already copies it.
+func scalarClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
+	return value
+}
+
+func typeClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
+	return plan.typeCloner(value, this)
+}
+
+func noClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
+	panic("No cloner for kind:" + plan.typ.Kind().String() + ":" + plan.typ.String())
+}
+
+func sliceClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
 	if value.IsNil() {
 		return value
 	}
-	newSlice := reflect.MakeSlice(reflect.SliceOf(value.Type().Elem()), value.Len(), value.Len())
-	for i := 0; i < value.Len(); i++ {
-		elem := value.Index(i)
-		elemClone := this.clone(elem, name, stopLoop)
-		newSlice.Index(i).Set(elemClone)
+	if value.Cap() == 0 || plan.typ.Elem().Size() == 0 {
+		return reflect.MakeSlice(plan.typ, value.Len(), value.Len())
+	}
+	if !state.aliased {
+		entry := &aliasEntry{clone: reflect.MakeSlice(plan.typ, value.Len(), value.Len()), source: value, path: path}
+		cloneSlots(this, plan, entry, 0, value.Len(), state, path)
+		return entry.clone
 	}
-	return newSlice
+	//the slices of the same backing array end at the same address, unless their capacity was limited
+	key := aliasKey{addr: value.Pointer() + uintptr(value.Cap())*plan.typ.Elem().Size(), typ: plan.typ}
+	entry, ok := state.seen[key]
+	//the clone covers the length of the first slice seen, a slice reaching beyond it gets its own backing array
+	if ok && value.Cap() <= entry.source.Cap() && entry.source.Cap()-value.Cap()+value.Len() <= entry.clone.Len() {
+		state.alias(entry, path, reflect.Slice)
+	} else {
+		entry = &aliasEntry{clone: reflect.MakeSlice(plan.typ, value.Len(), value.Len()), source: value, path: path}
+		state.see(key, entry)
+	}
+	offset := entry.source.Cap() - value.Cap()
+	cloneSlots(this, plan, entry, offset, offset+value.Len(), state, path)
+	return entry.clone.Slice(offset, offset+value.Len())
 }
 
-func (this *Cloner) ptrCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	if value.IsNil() {
+// cloneSlots clones the slots of the first slice seen that the slice refers to, that were not cloned yet.
+func cloneSlots(this *Cloner, plan *clonePlan, entry *aliasEntry, offset, end int, state *cloneState, path *equalPath) {
+	if end <= entry.cloned {
+		return
+	}
+	start := entry.cloned
+	//marked as cloned before cloning the elements, as an element may refer back to this slice
+	entry.cloned = end
+	if plan.elem.pointerFree && !state.hooked {
+		reflect.Copy(entry.clone.Slice(start, end), entry.source.Slice(start, end))
+		return
+	}
+	for i := start; i < end; i++ {
+		elemPath := path.key(i - offset)
+		if state.hooked {
+			hooked, ok := this.applyHook(entry.source.Index(i), elemPath)
+			if ok {
+				if hooked.IsValid() {
+					entry.clone.Index(i).Set(hooked)
+				}
+				continue
+			}
+		}
+		entry.clone.Index(i).Set(plan.elem.clone(this, plan.elem, entry.source.Index(i), state, elemPath))
+	}
+}
+
+func ptrClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
+	if value.IsNil() || plan.shared {
 		return value
 	}
 
-	p := strconv.Itoa(int(value.Pointer()))
-	exist, ok := stopLoop[p]
+	key := aliasKey{addr: value.Pointer(), typ: plan.typ}
+	exist, ok := state.seen[key]
 	if ok {
-		return exist
+		state.alias(exist, path, reflect.Ptr)
+		return exist.clone
 	}
 
-	newPtr := reflect.New(value.Elem().Type())
-	stopLoop[p] = newPtr
+	newPtr := reflect.New(plan.typ.Elem())
+	state.see(key, &aliasEntry{clone: newPtr, path: path})
 
-	newPtr.Elem().Set(this.clone(value.Elem(), name, stopLoop))
+	newPtr.Elem().Set(plan.elem.clone(this, plan.elem, value.Elem(), state, path))
 
 	return newPtr
 }
 
-func (this *Cloner) structCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	cloneStruct := reflect.New(value.Type()).Elem()
-	structType := value.Type()
-	for i := 0; i < structType.NumField(); i++ {
-		fieldValue := value.Field(i)
-		fieldName := structType.Field(i).Name
-		if SkipFieldByName(fieldName) {
-			continue
+func structClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
+	cloneStruct := reflect.New(plan.typ).Elem()
+	for _, field := range plan.fields {
+		fieldValue := value.Field(field.index)
+		if state.hooked {
+			hooked, ok := this.applyHook(fieldValue, path.field(field.name))
+			if ok {
+				if hooked.IsValid() {
+					cloneStruct.Field(field.index).Set(hooked)
+				}
+				continue
+			}
 		}
-		cloned := this.clone(fieldValue, structType.Field(i).Name, stopLoop)
-		if cloned.Kind() == reflect.Int32 {
-			cloneStruct.Field(i).SetInt(cloned.Int())
-		} else {
-			cloneStruct.Field(i).Set(cloned)
+		if field.plan.pointerFree {
+			cloneStruct.Field(field.index).Set(fieldValue)
+			continue
 		}
+		cloneStruct.Field(field.index).Set(field.plan.clone(this, field.plan, fieldValue, state, path.field(field.name)))
 	}
 	return cloneStruct
 }
 
-func (this *Cloner) mapCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
+func mapClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
 	if value.IsNil() {
 		return value
 	}
-	mapKeys := value.MapKeys()
-	mapClone := reflect.MakeMapWithSize(value.Type(), len(mapKeys))
-	for _, key := range mapKeys {
-		mapElem := value.MapIndex(key)
-		mapElemClone := this.clone(mapElem, name, stopLoop)
-		mapClone.SetMapIndex(key, mapElemClone)
+	key := aliasKey{addr: value.Pointer(), typ: plan.typ}
+	if state.aliased {
+		exist, ok := state.seen[key]
+		if ok {
+			state.alias(exist, path, reflect.Map)
+			return exist.clone
+		}
+	}
+	mapClone := reflect.MakeMapWithSize(plan.typ, value.Len())
+	if state.aliased {
+		state.see(key, &aliasEntry{clone: mapClone, path: path})
+	}
+	iter := value.MapRange()
+	for iter.Next() {
+		var entryPath *equalPath
+		if path != nil {
+			entryPath = path.key(iter.Key().Interface())
+		}
+		if state.hooked {
+			hooked, ok := this.applyHook(iter.Value(), entryPath)
+			if ok {
+				if hooked.IsValid() {
+					mapClone.SetMapIndex(iter.Key(), hooked)
+				}
+				continue
+			}
+		}
+		mapClone.SetMapIndex(iter.Key(), plan.elem.clone(this, plan.elem, iter.Value(), state, entryPath))
 	}
 	return mapClone
 }
 
-func (this *Cloner) intCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Int()
-	return reflect.ValueOf(int(i))
-}
-
-func (this *Cloner) uintCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Uint()
-	return reflect.ValueOf(uint(i))
-}
-
-func (this *Cloner) uint32Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Uint()
-	return reflect.ValueOf(uint32(i))
-}
-
-func (this *Cloner) uint64Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Uint()
-	return reflect.ValueOf(uint64(i))
-}
-
-func (this *Cloner) float32Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Float()
-	return reflect.ValueOf(float32(i))
-}
-
-func (this *Cloner) float64Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Float()
-	return reflect.ValueOf(float64(i))
-}
-
-func (this *Cloner) boolCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	b := value.Bool()
-	return reflect.ValueOf(b)
-}
-
-func (this *Cloner) int32Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Int()
-	return reflect.ValueOf(int32(i))
-}
-
-func (this *Cloner) int64Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Int()
-	return reflect.ValueOf(int64(i))
-}
-
-func (this *Cloner) stringCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	s := value.String()
-	return reflect.ValueOf(s)
-}
-
-func (this *Cloner) int8Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Int()
-	return reflect.ValueOf(int8(i))
-}
-
-func (this *Cloner) int16Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Int()
-	return reflect.ValueOf(int16(i))
-}
-
-func (this *Cloner) uint8Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Uint()
-	return reflect.ValueOf(uint8(i))
-}
-
-func (this *Cloner) uint16Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	i := value.Uint()
-	return reflect.ValueOf(uint16(i))
-}
-
-func (this *Cloner) complex64Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	c := value.Complex()
-	return reflect.ValueOf(complex64(c))
-}
-
-func (this *Cloner) complex128Cloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	c := value.Complex()
-	return reflect.ValueOf(complex128(c))
-}
-
-func (this *Cloner) arrayCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	arrayType := value.Type()
-	newArray := reflect.New(arrayType).Elem()
+func arrayClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
+	newArray := reflect.New(plan.typ).Elem()
 	for i := 0; i < value.Len(); i++ {
-		elem := value.Index(i)
-		elemClone := this.clone(elem, name, stopLoop)
-		newArray.Index(i).Set(elemClone)
+		if state.hooked {
+			hooked, ok := this.applyHook(value.Index(i), path.key(i))
+			if ok {
+				if hooked.IsValid() {
+					newArray.Index(i).Set(hooked)
+				}
+				continue
+			}
+		}
+		newArray.Index(i).Set(plan.elem.clone(this, plan.elem, value.Index(i), state, path.key(i)))
 	}
 	return newArray
 }
 
-func (this *Cloner) interfaceCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
+func interfaceClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
 	if value.IsNil() {
 		return value
 	}
-	// Get the concrete value inside the interface
-	concreteValue := value.Elem()
-	// Clone the concrete value
-	clonedConcrete := this.clone(concreteValue, name, stopLoop)
-	// Return it wrapped in the same interface type
-	return clonedConcrete
+	// The concrete type is only known per value, so its plan is looked up here
+	return this.clone(value.Elem(), state, path)
 }
 
-func (this *Cloner) chanCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
+func chanClone(this *Cloner, plan *clonePlan, value reflect.Value, state *cloneState, path *equalPath) reflect.Value {
 	if value.IsNil() {
 		return value
 	}
-	// Create a new channel of the same type
 	// Note: We can't clone channel contents, so we just create a new empty channel
-	chanType := value.Type()
-	newChan := reflect.MakeChan(chanType, 0)
-	return newChan
-}
-
-func (this *Cloner) funcCloner(value reflect.Value, name string, stopLoop map[string]reflect.Value) reflect.Value {
-	if value.IsNil() {
-		return value
-	}
-	// Functions can't be truly cloned in Go, so we return the same function
-	return value
+	return reflect.MakeChan(plan.typ, 0)
 }
 
 func SkipFieldByName(fieldName string) bool {
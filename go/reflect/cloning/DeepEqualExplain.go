@@ -0,0 +1,145 @@
+package cloning
+
+import (
+	"reflect"
+
+	strings2 "github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Difference is a value that differs between the two compared instances.
+// Path is in a property id style, e.g. "mymodel.mymap<{24}key>.field".
+// A missing map entry or slice element is nil on the side it is missing from.
+type Difference struct {
+	Path  string
+	ASide interface{}
+	ZSide interface{}
+}
+
+func (this *Difference) String() string {
+	str := strings2.New(this.Path)
+	str.Add(": aSide=").Add(str.StringOf(this.ASide)).Add(" zSide=").Add(str.StringOf(this.ZSide))
+	return str.String()
+}
+
+type explainer struct {
+	deepEqual *DeepEqual
+	all       bool
+	diffs     []*Difference
+}
+
+// Explain returns the first difference between aSide and zSide, or nil if they are equal.
+func (this *DeepEqual) Explain(aSide, zSide interface{}) *Difference {
+	diffs := this.explain(aSide, zSide, false)
+	if len(diffs) == 0 {
+		return nil
+	}
+	return diffs[0]
+}
+
+// ExplainAll returns every difference between aSide and zSide.
+func (this *DeepEqual) ExplainAll(aSide, zSide interface{}) []*Difference {
+	return this.explain(aSide, zSide, true)
+}
+
+func (this *DeepEqual) explain(aSide, zSide interface{}, all bool) []*Difference {
+	exp := &explainer{deepEqual: this, all: all}
+	aSideValue := reflect.ValueOf(aSide)
+	zSideValue := reflect.ValueOf(zSide)
+	if aSideValue.IsValid() {
+		exp.diff(aSideValue, zSideValue, rootPath(aSideValue))
+	} else {
+		exp.diff(aSideValue, zSideValue, rootPath(zSideValue))
+	}
+	return exp.diffs
+}
+
+// diff walks the containers to find where the values differ, the leaf values are compared by DeepEqual.
+// It returns false when done, i.e. a difference was found and not all differences were requested.
+func (this *explainer) diff(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	if !aSideValue.IsValid() || !zSideValue.IsValid() || aSideValue.Kind() != zSideValue.Kind() {
+		return this.compare(aSideValue, zSideValue, path)
+	}
+	switch aSideValue.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if aSideValue.IsNil() || zSideValue.IsNil() ||
+			(aSideValue.Kind() == reflect.Interface && aSideValue.Elem().Type() != zSideValue.Elem().Type()) {
+			return this.compare(aSideValue, zSideValue, path)
+		}
+		return this.diff(aSideValue.Elem(), zSideValue.Elem(), path)
+	case reflect.Struct:
+		if aSideValue.Type() != zSideValue.Type() {
+			return this.compare(aSideValue, zSideValue, path)
+		}
+		for i := 0; i < aSideValue.NumField(); i++ {
+			fieldName := aSideValue.Type().Field(i).Name
+			if SkipFieldByName(fieldName) {
+				continue
+			}
+			fieldPath := path.field(fieldName)
+			if this.deepEqual.options.Ignored(fieldPath.id, fieldPath.node) {
+				continue
+			}
+			if !this.diff(aSideValue.Field(i), zSideValue.Field(i), fieldPath) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		options := this.deepEqual.options
+		if aSideValue.Kind() == reflect.Slice && (aSideValue.IsNil() || zSideValue.IsNil() ||
+			(options != nil && options.UnorderedSlices)) {
+			return this.compare(aSideValue, zSideValue, path)
+		}
+		size := aSideValue.Len()
+		if zSideValue.Len() > size {
+			size = zSideValue.Len()
+		}
+		for i := 0; i < size; i++ {
+			if !this.diff(elemAt(aSideValue, i), elemAt(zSideValue, i), path.key(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if aSideValue.IsNil() || zSideValue.IsNil() {
+			return this.compare(aSideValue, zSideValue, path)
+		}
+		for _, key := range aSideValue.MapKeys() {
+			if !this.diff(aSideValue.MapIndex(key), zSideValue.MapIndex(key), path.key(key.Interface())) {
+				return false
+			}
+		}
+		for _, key := range zSideValue.MapKeys() {
+			if aSideValue.MapIndex(key).IsValid() {
+				continue
+			}
+			if !this.diff(reflect.Value{}, zSideValue.MapIndex(key), path.key(key.Interface())) {
+				return false
+			}
+		}
+		return true
+	}
+	return this.compare(aSideValue, zSideValue, path)
+}
+
+func (this *explainer) compare(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	if this.deepEqual.equal(aSideValue, zSideValue, path) {
+		return true
+	}
+	this.diffs = append(this.diffs, &Difference{Path: path.id, ASide: interfaceOf(aSideValue), ZSide: interfaceOf(zSideValue)})
+	return this.all
+}
+
+func elemAt(value reflect.Value, i int) reflect.Value {
+	if i >= value.Len() {
+		return reflect.Value{}
+	}
+	return value.Index(i)
+}
+
+func interfaceOf(value reflect.Value) interface{} {
+	if !value.IsValid() || !value.CanInterface() {
+		return nil
+	}
+	return value.Interface()
+}
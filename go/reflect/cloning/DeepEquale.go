@@ -5,7 +5,8 @@ import (
 )
 
 type DeepEqual struct {
-	comparators map[reflect.Kind]func(reflect.Value, reflect.Value) bool
+	comparators map[reflect.Kind]func(reflect.Value, reflect.Value, *equalPath) bool
+	options     *EqualOptions
 }
 
 func NewDeepEqual() *DeepEqual {
@@ -15,14 +16,19 @@ func NewDeepEqual() *DeepEqual {
 }
 
 func (this *DeepEqual) initCloners() {
-	this.comparators = make(map[reflect.Kind]func(reflect.Value, reflect.Value) bool)
+	this.comparators = make(map[reflect.Kind]func(reflect.Value, reflect.Value, *equalPath) bool)
 	this.comparators[reflect.Int] = this.intComp
+	this.comparators[reflect.Int8] = this.intComp
+	this.comparators[reflect.Int16] = this.intComp
 	this.comparators[reflect.Int32] = this.intComp
 	this.comparators[reflect.Int64] = this.intComp
 
 	this.comparators[reflect.Uint] = this.uintComp
+	this.comparators[reflect.Uint8] = this.uintComp
+	this.comparators[reflect.Uint16] = this.uintComp
 	this.comparators[reflect.Uint32] = this.uintComp
 	this.comparators[reflect.Uint64] = this.uintComp
+	this.comparators[reflect.Uintptr] = this.uintComp
 
 	this.comparators[reflect.String] = this.stringComp
 
@@ -31,6 +37,9 @@ func (this *DeepEqual) initCloners() {
 	this.comparators[reflect.Float32] = this.floatComp
 	this.comparators[reflect.Float64] = this.floatComp
 
+	this.comparators[reflect.Complex64] = this.complexComp
+	this.comparators[reflect.Complex128] = this.complexComp
+
 	this.comparators[reflect.Ptr] = this.ptrComp
 
 	this.comparators[reflect.Struct] = this.structComp
@@ -39,15 +48,51 @@ func (this *DeepEqual) initCloners() {
 
 	this.comparators[reflect.Map] = this.mapComp
 
+	this.comparators[reflect.Array] = this.arrayComp
+
+	this.comparators[reflect.Interface] = this.interfaceComp
+
+	this.comparators[reflect.Chan] = this.pointerComp
+	this.comparators[reflect.UnsafePointer] = this.pointerComp
+
+	this.comparators[reflect.Func] = this.funcComp
+}
+
+// SetOptions sets the options of the comparison, nil is a strict comparison.
+func (this *DeepEqual) SetOptions(options *EqualOptions) {
+	this.options = options
+}
+
+func (this *DeepEqual) Options() *EqualOptions {
+	return this.options
 }
 
 func (this *DeepEqual) Equal(aSide, zSide interface{}) bool {
 	aSideValue := reflect.ValueOf(aSide)
 	zSideValue := reflect.ValueOf(zSide)
-	return this.equal(aSideValue, zSideValue)
+	var path *equalPath
+	if this.tracking() {
+		path = rootPath(aSideValue)
+	}
+	return this.equal(aSideValue, zSideValue, path)
+}
+
+// EqualAt compares values that are located at the given property id & node path,
+// so ignored property ids under them are matched.
+func (this *DeepEqual) EqualAt(aSide, zSide interface{}, id, nodePath string) bool {
+	var path *equalPath
+	if this.tracking() {
+		path = &equalPath{id: id, node: nodePath}
+	}
+	return this.equal(reflect.ValueOf(aSide), reflect.ValueOf(zSide), path)
+}
+
+// tracking returns true if the paths of the compared values are needed to match ignored property ids.
+func (this *DeepEqual) tracking() bool {
+	return this.options != nil && len(this.options.IgnoreIds) > 0
 }
 
-func (this *DeepEqual) equal(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) equal(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	if aSideValue.IsValid() && !zSideValue.IsValid() {
 		return false
 	}
@@ -66,32 +111,36 @@ func (this *DeepEqual) equal(aSideValue, zSideValue reflect.Value) bool {
 	if comparator == nil {
 		panic("No comparator for kind:" + kind.String() + ", please add it!")
 	}
-	return comparator(aSideValue, zSideValue)
+	return comparator(aSideValue, zSideValue, path)
 }
 
 //---------------------------------------------------------------------------
 
-func (this *DeepEqual) intComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) intComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	return aSideValue.Int() == zSideValue.Int()
 }
 
-func (this *DeepEqual) uintComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) uintComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	return aSideValue.Uint() == zSideValue.Uint()
 }
 
-func (this *DeepEqual) stringComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) stringComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	return aSideValue.String() == zSideValue.String()
 }
 
-func (this *DeepEqual) boolComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) boolComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	return aSideValue.Bool() == zSideValue.Bool()
 }
 
-func (this *DeepEqual) floatComp(aSideValue, zSideValue reflect.Value) bool {
-	return aSideValue.Float() == zSideValue.Float()
+func (this *DeepEqual) floatComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	return this.options.FloatEqual(aSideValue.Float(), zSideValue.Float())
 }
 
-func (this *DeepEqual) ptrComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) complexComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	return aSideValue.Complex() == zSideValue.Complex()
+}
+
+func (this *DeepEqual) ptrComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	if aSideValue.IsNil() && !zSideValue.IsNil() {
 		return false
 	}
@@ -101,10 +150,10 @@ func (this *DeepEqual) ptrComp(aSideValue, zSideValue reflect.Value) bool {
 	if aSideValue.IsNil() && zSideValue.IsNil() {
 		return true
 	}
-	return this.equal(aSideValue.Elem(), zSideValue.Elem())
+	return this.equal(aSideValue.Elem(), zSideValue.Elem(), path)
 }
 
-func (this *DeepEqual) structComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) structComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
 	if aSideValue.Type().Name() != zSideValue.Type().Name() {
 		return false
 	}
@@ -113,9 +162,13 @@ func (this *DeepEqual) structComp(aSideValue, zSideValue reflect.Value) bool {
 		if SkipFieldByName(fieldName) {
 			continue
 		}
+		fieldPath := path.field(fieldName)
+		if fieldPath != nil && this.options.Ignored(fieldPath.id, fieldPath.node) {
+			continue
+		}
 		aFieldValue := aSideValue.Field(i)
 		zFieldValue := zSideValue.Field(i)
-		eq := this.equal(aFieldValue, zFieldValue)
+		eq := this.equal(aFieldValue, zFieldValue, fieldPath)
 		if !eq {
 			return false
 		}
@@ -123,7 +176,10 @@ func (this *DeepEqual) structComp(aSideValue, zSideValue reflect.Value) bool {
 	return true
 }
 
-func (this *DeepEqual) sliceComp(aSideValue, zSideValue reflect.Value) bool {
+func (this *DeepEqual) sliceComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	if this.nilIsEmpty(aSideValue, zSideValue) {
+		return true
+	}
 	if aSideValue.IsNil() && !zSideValue.IsNil() {
 		return false
 	}
@@ -138,10 +194,14 @@ func (this *DeepEqual) sliceComp(aSideValue, zSideValue reflect.Value) bool {
 		return false
 	}
 
+	if this.options != nil && this.options.UnorderedSlices {
+		return this.unorderedComp(aSideValue, zSideValue, path)
+	}
+
 	for i := 0; i < aSideValue.Len(); i++ {
 		aSideCel := aSideValue.Index(i)
 		zSideCel := zSideValue.Index(i)
-		eq := this.equal(aSideCel, zSideCel)
+		eq := this.equal(aSideCel, zSideCel, path.key(i))
 		if !eq {
 			return false
 		}
@@ -149,7 +209,33 @@ func (this *DeepEqual) sliceComp(aSideValue, zSideValue reflect.Value) bool {
 	return true
 }
 
-func (this *DeepEqual) mapComp(aSideValue, zSideValue reflect.Value) bool {
+// unorderedComp compares the slices as multisets, every element must match a distinct element of the other slice.
+func (this *DeepEqual) unorderedComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	matched := make([]bool, zSideValue.Len())
+	for i := 0; i < aSideValue.Len(); i++ {
+		found := false
+		for j := 0; j < zSideValue.Len(); j++ {
+			if !matched[j] && this.equal(aSideValue.Index(i), zSideValue.Index(j), path.key(i)) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *DeepEqual) nilIsEmpty(aSideValue, zSideValue reflect.Value) bool {
+	return this.options != nil && this.options.NilIsEmpty && aSideValue.Len() == 0 && zSideValue.Len() == 0
+}
+
+func (this *DeepEqual) mapComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	if this.nilIsEmpty(aSideValue, zSideValue) {
+		return true
+	}
 	if aSideValue.IsNil() && !zSideValue.IsNil() {
 		return false
 	}
@@ -169,10 +255,43 @@ func (this *DeepEqual) mapComp(aSideValue, zSideValue reflect.Value) bool {
 	for _, key := range mapKeysAside {
 		aSideV := aSideValue.MapIndex(key)
 		zSideV := zSideValue.MapIndex(key)
-		eq := this.equal(aSideV, zSideV)
+		eq := this.equal(aSideV, zSideV, path.key(key.Interface()))
 		if !eq {
 			return false
 		}
 	}
 	return true
 }
+
+func (this *DeepEqual) arrayComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	if aSideValue.Len() != zSideValue.Len() {
+		return false
+	}
+	for i := 0; i < aSideValue.Len(); i++ {
+		eq := this.equal(aSideValue.Index(i), zSideValue.Index(i), path.key(i))
+		if !eq {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *DeepEqual) interfaceComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	if aSideValue.IsNil() || zSideValue.IsNil() {
+		return aSideValue.IsNil() == zSideValue.IsNil()
+	}
+	if aSideValue.Elem().Type() != zSideValue.Elem().Type() {
+		return false
+	}
+	return this.equal(aSideValue.Elem(), zSideValue.Elem(), path)
+}
+
+// pointerComp compares channels & unsafe pointers by identity, as there is no value to compare.
+func (this *DeepEqual) pointerComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	return aSideValue.Pointer() == zSideValue.Pointer()
+}
+
+// funcComp follows the go semantics where functions are only equal if both are nil.
+func (this *DeepEqual) funcComp(aSideValue, zSideValue reflect.Value, path *equalPath) bool {
+	return aSideValue.IsNil() && zSideValue.IsNil()
+}
@@ -0,0 +1,97 @@
+package cloning
+
+import (
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// EqualOptions tune the DeepEqual comparison.
+type EqualOptions struct {
+	//IgnoreIds are property ids that are not compared. An id with keys, e.g. "mymodel.mymap<{24}key>.field",
+	//ignores a single entry, while an id without keys, e.g. "mymodel.mymap.field", ignores it in every entry.
+	IgnoreIds map[string]bool
+	//NilIsEmpty treats a nil slice or map as equal to an empty one.
+	NilIsEmpty bool
+	//UnorderedSlices compares slices as multisets, ignoring the order of their elements.
+	UnorderedSlices bool
+	//FloatEpsilon is the max difference between two floats that are still equal.
+	FloatEpsilon float64
+}
+
+// IgnoreId adds property ids that are not compared.
+func (this *EqualOptions) IgnoreId(ids ...string) {
+	if this.IgnoreIds == nil {
+		this.IgnoreIds = make(map[string]bool)
+	}
+	for _, id := range ids {
+		this.IgnoreIds[id] = true
+	}
+}
+
+// IgnoreDecorated ignores every node under, and including, the given node that has the decorator.
+func (this *EqualOptions) IgnoreDecorated(node *l8reflect.L8Node, decoratorType l8reflect.L8DecoratorType) {
+	if node == nil {
+		return
+	}
+	if node.Decorators != nil {
+		if _, ok := node.Decorators[int32(decoratorType)]; ok {
+			this.IgnoreId(helping.NodeCacheKey(node))
+		}
+	}
+	for _, attr := range node.Attributes {
+		this.IgnoreDecorated(attr, decoratorType)
+	}
+}
+
+// Ignored returns true if the property id, or its node path without the keys, should not be compared.
+func (this *EqualOptions) Ignored(id, nodePath string) bool {
+	if this == nil || len(this.IgnoreIds) == 0 {
+		return false
+	}
+	return this.IgnoreIds[id] || this.IgnoreIds[nodePath]
+}
+
+// FloatEqual returns true if the floats are equal within the epsilon.
+func (this *EqualOptions) FloatEqual(a, b float64) bool {
+	if this == nil || this.FloatEpsilon == 0 {
+		return a == b
+	}
+	return math.Abs(a-b) <= this.FloatEpsilon
+}
+
+// equalPath tracks the property id and node path of the compared values, it is nil when no ids are ignored.
+type equalPath struct {
+	id   string
+	node string
+}
+
+func (this *equalPath) field(name string) *equalPath {
+	if this == nil {
+		return nil
+	}
+	name = strings.ToLower(name)
+	return &equalPath{id: this.id + "." + name, node: this.node + "." + name}
+}
+
+func (this *equalPath) key(key interface{}) *equalPath {
+	if this == nil {
+		return nil
+	}
+	return &equalPath{id: helping.KeyId(this.id, key), node: this.node}
+}
+
+func rootPath(value reflect.Value) *equalPath {
+	if !value.IsValid() {
+		return &equalPath{}
+	}
+	t := value.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := strings.ToLower(t.Name())
+	return &equalPath{id: name, node: name}
+}
@@ -0,0 +1,16 @@
+package cloning
+
+import (
+	"errors"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// Clone deep clones the value with the cloner and returns it as T, or an error if the clone is not a T.
+func Clone[T any](cloner *Cloner, value T) (T, error) {
+	if cloner == nil {
+		var zero T
+		return zero, errors.New("cloner is nil")
+	}
+	return helping.As[T](cloner.Clone(value))
+}
@@ -0,0 +1,75 @@
+package cloning
+
+import (
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// TypeCloner clones a value of a specific type, it may use the cloner to deep clone the values nested in it.
+type TypeCloner func(value reflect.Value, cloner *Cloner) reflect.Value
+
+// AddTypeCloner registers a clone function for a concrete type, taking precedence over the clone by kind.
+// Registering a pointer type, e.g. *time.Location, lets the function decide if the pointer is shared.
+func (this *Cloner) AddTypeCloner(typ reflect.Type, typeCloner TypeCloner) {
+	if this.typeCloners == nil {
+		this.typeCloners = make(map[reflect.Type]TypeCloner)
+	}
+	this.typeCloners[typ] = typeCloner
+	this.resetPlans()
+}
+
+// initTypeCloners registers the standard library types that can't be cloned field by field,
+// as their state is in unexported fields that would otherwise be skipped.
+func (this *Cloner) initTypeCloners() {
+	//immutable values are copied as is
+	this.AddTypeCloner(reflect.TypeOf(time.Time{}), copyValue)
+	this.AddTypeCloner(reflect.TypeOf(time.Duration(0)), copyValue)
+
+	//immutable or concurrent safe instances are shared
+	this.AddTypeCloner(reflect.TypeOf(&time.Location{}), shareValue)
+	this.AddTypeCloner(reflect.TypeOf(&regexp.Regexp{}), shareValue)
+	this.AddTypeCloner(reflect.TypeOf(&url.Userinfo{}), shareValue)
+
+	this.AddTypeCloner(reflect.TypeOf(&big.Int{}), func(value reflect.Value, cloner *Cloner) reflect.Value {
+		if value.IsNil() {
+			return value
+		}
+		return reflect.ValueOf(new(big.Int).Set(value.Interface().(*big.Int)))
+	})
+	this.AddTypeCloner(reflect.TypeOf(&big.Float{}), func(value reflect.Value, cloner *Cloner) reflect.Value {
+		if value.IsNil() {
+			return value
+		}
+		return reflect.ValueOf(new(big.Float).Copy(value.Interface().(*big.Float)))
+	})
+	this.AddTypeCloner(reflect.TypeOf(&big.Rat{}), func(value reflect.Value, cloner *Cloner) reflect.Value {
+		if value.IsNil() {
+			return value
+		}
+		return reflect.ValueOf(new(big.Rat).Set(value.Interface().(*big.Rat)))
+	})
+
+	//a lock or a wait group must not be copied, the clone gets a new one
+	this.AddTypeCloner(reflect.TypeOf(sync.Mutex{}), zeroValue)
+	this.AddTypeCloner(reflect.TypeOf(sync.RWMutex{}), zeroValue)
+	this.AddTypeCloner(reflect.TypeOf(sync.WaitGroup{}), zeroValue)
+	this.AddTypeCloner(reflect.TypeOf(sync.Once{}), zeroValue)
+}
+
+func copyValue(value reflect.Value, cloner *Cloner) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+	return copied
+}
+
+func shareValue(value reflect.Value, cloner *Cloner) reflect.Value {
+	return value
+}
+
+func zeroValue(value reflect.Value, cloner *Cloner) reflect.Value {
+	return reflect.New(value.Type()).Elem()
+}
@@ -0,0 +1,101 @@
+package hashing
+
+import "sort"
+
+// HashTree is a merkle tree of an instance, the hash of every subtree is derived from the hashes under it.
+// Two replicas can compare their root hashes and only walk the subtrees that differ.
+type HashTree struct {
+	PropertyId string
+	Hash       uint64
+	Children   []*HashTree
+	//own is the hash of the values of this subtree that are not subtrees themselves
+	own   uint64
+	index map[string]*HashTree
+}
+
+func (this *HashTree) size() int {
+	if this == nil {
+		return 0
+	}
+	return len(this.Children)
+}
+
+func (this *HashTree) add(propertyId string) *HashTree {
+	if this == nil {
+		return nil
+	}
+	child := &HashTree{PropertyId: propertyId, index: this.index}
+	this.Children = append(this.Children, child)
+	this.index[propertyId] = child
+	return child
+}
+
+func (this *HashTree) set(hash, own uint64) uint64 {
+	if this == nil {
+		return hash
+	}
+	this.Hash = hash
+	this.own = own
+	sort.Slice(this.Children, func(i, j int) bool { return this.Children[i].PropertyId < this.Children[j].PropertyId })
+	return hash
+}
+
+// Find returns the subtree of the property id, or nil if there is no such subtree.
+func (this *HashTree) Find(propertyId string) *HashTree {
+	if this == nil {
+		return nil
+	}
+	return this.index[propertyId]
+}
+
+// Hashes returns the hashes of all the subtrees, keyed by their property id.
+func (this *HashTree) Hashes() map[string]uint64 {
+	result := make(map[string]uint64)
+	if this == nil {
+		return result
+	}
+	for id, tree := range this.index {
+		result[id] = tree.Hash
+	}
+	return result
+}
+
+// Diff returns the property ids of the deepest subtrees that differ between this tree and the other tree,
+// only walking the subtrees whose hashes differ.
+func (this *HashTree) Diff(other *HashTree) []string {
+	result := make([]string, 0)
+	if this == nil || other == nil {
+		if this != nil {
+			result = append(result, this.PropertyId)
+		} else if other != nil {
+			result = append(result, other.PropertyId)
+		}
+		return result
+	}
+	if this.Hash == other.Hash {
+		return result
+	}
+	children := make(map[string]*HashTree)
+	for _, child := range other.Children {
+		children[child.PropertyId] = child
+	}
+	for _, child := range this.Children {
+		otherChild, ok := children[child.PropertyId]
+		delete(children, child.PropertyId)
+		if !ok {
+			result = append(result, child.PropertyId)
+			continue
+		}
+		result = append(result, child.Diff(otherChild)...)
+	}
+	for _, child := range other.Children {
+		if _, ok := children[child.PropertyId]; ok {
+			result = append(result, child.PropertyId)
+		}
+	}
+	//the difference is in the leaf values of this subtree
+	if this.own != other.own || len(result) == 0 {
+		result = append([]string{this.PropertyId}, result...)
+	}
+	return result
+}
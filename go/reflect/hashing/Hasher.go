@@ -0,0 +1,337 @@
+package hashing
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
+)
+
+// Hasher calculates a deterministic, process independent, structural hash of an instance.
+// Map entries are hashed regardless of their iteration order and ignored field names are skipped.
+// A pointer or a map that is reached again through itself is hashed as a cycle instead of walking it again.
+type Hasher struct {
+	resources  ifs.IResources
+	fields     map[reflect.Type][]hashField
+	fieldsLock sync.RWMutex
+}
+
+// hashField is a field of a struct type that is hashed, reflect.Type.Field allocates so the fields are cached per type.
+type hashField struct {
+	index int
+	name  string
+	lower string
+}
+
+// visit is a pointer or a map on the path from the root to the value being hashed.
+type visit struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func NewHasher(resources ifs.IResources) *Hasher {
+	hasher := &Hasher{}
+	hasher.resources = resources
+	hasher.fields = make(map[reflect.Type][]hashField)
+	return hasher
+}
+
+func (this *Hasher) fieldsOf(typ reflect.Type) []hashField {
+	this.fieldsLock.RLock()
+	fields, ok := this.fields[typ]
+	this.fieldsLock.RUnlock()
+	if ok {
+		return fields
+	}
+	fields = make([]hashField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if helping.IgnoreName(name) {
+			continue
+		}
+		fields = append(fields, hashField{index: i, name: name, lower: strings.ToLower(name)})
+	}
+	this.fieldsLock.Lock()
+	this.fields[typ] = fields
+	this.fieldsLock.Unlock()
+	return fields
+}
+
+// Hash returns the structural hash of any, a pointer has the hash of the value it points to.
+func (this *Hasher) Hash(any interface{}) uint64 {
+	return this.hash(elem(reflect.ValueOf(any)), "", nil, nil, make(map[visit]bool))
+}
+
+// HashTree returns the structural hash of any with the hashes of its subtrees, keyed by their property id.
+// Every struct, map & slice in the instance is a subtree. The elements of a primary keyed slice
+// are keyed by their primary key, as the Updater does.
+func (this *Hasher) HashTree(any interface{}) *HashTree {
+	value := elem(reflect.ValueOf(any))
+	root := &HashTree{index: make(map[string]*HashTree)}
+	id, node := this.root(value)
+	this.hash(value, id, node, root, make(map[visit]bool))
+	if len(root.Children) == 0 {
+		return nil
+	}
+	tree := root.Children[0]
+	tree.index = root.index
+	return tree
+}
+
+// root is the property id of the root instance, including its primary key as the Updater does, and its node.
+func (this *Hasher) root(value reflect.Value) (string, *l8reflect.L8Node) {
+	if !value.IsValid() {
+		return "", nil
+	}
+	id := strings.ToLower(value.Type().Name())
+	if value.Kind() != reflect.Struct {
+		return id, nil
+	}
+	node := this.nodeOf(value.Type())
+	if node == nil {
+		return id, nil
+	}
+	key := helping.PrimaryDecorator(node, value, this.resources.Registry())
+	if key == nil {
+		return id, node
+	}
+	return helping.KeyId(id, key), node
+}
+
+// nodeOf is the node of the struct type, if the type was inspected.
+func (this *Hasher) nodeOf(typ reflect.Type) *l8reflect.L8Node {
+	if this.resources == nil {
+		return nil
+	}
+	node, _ := this.resources.Introspector().Node(typ.Name())
+	return node
+}
+
+func (this *Hasher) hash(value reflect.Value, id string, node *l8reflect.L8Node, tree *HashTree, visiting map[visit]bool) uint64 {
+	h := newFnv()
+	if !value.IsValid() {
+		h.writeUint(uint64(reflect.Invalid))
+		return uint64(h)
+	}
+	kind := value.Kind()
+	h.writeUint(uint64(kind))
+	switch kind {
+	case reflect.Bool:
+		if value.Bool() {
+			h.writeUint(1)
+		} else {
+			h.writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.writeUint(uint64(value.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.writeUint(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.writeUint(math.Float64bits(value.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		h.writeUint(math.Float64bits(real(value.Complex())))
+		h.writeUint(math.Float64bits(imag(value.Complex())))
+	case reflect.String:
+		h.writeString(value.String())
+	case reflect.Ptr:
+		if value.IsNil() {
+			h.writeUint(0)
+			break
+		}
+		self := visit{addr: value.Pointer(), typ: value.Type()}
+		if visiting[self] {
+			h.writeString("cycle")
+			break
+		}
+		visiting[self] = true
+		h.writeUint(this.hash(value.Elem(), id, node, tree, visiting))
+		delete(visiting, self)
+	case reflect.Interface:
+		if value.IsNil() {
+			h.writeUint(0)
+		} else {
+			//a struct behind an interface is diffed by the node of its type
+			elemNode := node
+			if e := elem(value.Elem()); e.Kind() == reflect.Struct {
+				elemNode = this.nodeOf(e.Type())
+			}
+			h.writeString(value.Elem().Type().String())
+			h.writeUint(this.hash(value.Elem(), id, elemNode, tree, visiting))
+		}
+	case reflect.Struct:
+		subTree := tree.add(id)
+		own := newFnv()
+		h.writeString(value.Type().Name())
+		for _, field := range this.fieldsOf(value.Type()) {
+			var fieldNode *l8reflect.L8Node
+			if node != nil {
+				fieldNode = node.Attributes[field.name]
+			}
+			fieldId := ""
+			if id != "" {
+				fieldId = id + "." + field.lower
+			}
+			size := subTree.size()
+			fieldHash := this.hash(value.Field(field.index), fieldId, fieldNode, subTree, visiting)
+			h.writeString(field.name)
+			h.writeUint(fieldHash)
+			if subTree.size() == size {
+				own.writeString(field.name)
+				own.writeUint(fieldHash)
+			}
+		}
+		return subTree.set(uint64(h), uint64(own))
+	case reflect.Slice, reflect.Array:
+		if kind == reflect.Slice && value.IsNil() {
+			h.writeUint(0)
+			break
+		}
+		h.writeUint(uint64(value.Len()))
+		//a []byte is a single leaf value
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			for i := 0; i < value.Len(); i++ {
+				h.writeByte(byte(value.Index(i).Uint()))
+			}
+			break
+		}
+		var subTree *HashTree
+		if kind == reflect.Slice {
+			subTree = tree.add(id)
+		}
+		keys := this.sliceKeys(node, value)
+		own := newFnv()
+		own.writeUint(uint64(value.Len()))
+		for i := 0; i < value.Len(); i++ {
+			var key interface{} = i
+			if keys != nil {
+				key = keys[i]
+			}
+			size := subTree.size()
+			elemHash := this.hash(value.Index(i), helping.KeyId(id, key), node, subTree, visiting)
+			h.writeUint(elemHash)
+			if subTree.size() == size {
+				own.writeUint(elemHash)
+			}
+		}
+		if kind == reflect.Slice {
+			return subTree.set(uint64(h), uint64(own))
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			h.writeUint(0)
+			break
+		}
+		self := visit{addr: value.Pointer(), typ: value.Type()}
+		if visiting[self] {
+			h.writeString("cycle")
+			break
+		}
+		visiting[self] = true
+		defer delete(visiting, self)
+		subTree := tree.add(id)
+		//sort the hashes of the entries, so the iteration order does not matter
+		entries := make([]uint64, 0, value.Len())
+		owns := make([]uint64, 0)
+		for _, key := range value.MapKeys() {
+			size := subTree.size()
+			entry := newFnv()
+			entry.writeUint(this.hash(key, "", nil, nil, visiting))
+			entry.writeUint(this.hash(value.MapIndex(key), helping.KeyId(id, key.Interface()), node, subTree, visiting))
+			entries = append(entries, uint64(entry))
+			if subTree.size() == size {
+				owns = append(owns, uint64(entry))
+			}
+		}
+		return subTree.set(sortedHash(h, entries), sortedHash(newFnv(), owns))
+	default:
+		//channels, functions & unsafe pointers have no value that is stable across processes
+		if value.IsNil() {
+			h.writeUint(0)
+		} else {
+			h.writeUint(1)
+		}
+	}
+	return uint64(h)
+}
+
+// sliceKeys returns the primary keys of the elements of a primary keyed slice, or nil if the slice
+// is diffed by index, as when an element is nil or two elements have the same key.
+func (this *Hasher) sliceKeys(node *l8reflect.L8Node, value reflect.Value) []interface{} {
+	if node == nil || !node.IsSlice || !node.IsStruct || introspecting.NoNestedInspection(node) ||
+		value.Kind() != reflect.Slice || value.Type().Elem().Kind() != reflect.Ptr {
+		return nil
+	}
+	registry := this.resources.Registry()
+	if len(helping.PrimaryDecoratorFields(node, registry)) == 0 {
+		return nil
+	}
+	keys := make([]interface{}, value.Len())
+	seen := make(map[interface{}]bool)
+	for i := 0; i < value.Len(); i++ {
+		e := value.Index(i)
+		if e.IsNil() {
+			return nil
+		}
+		key := helping.PrimaryDecorator(node, e.Elem(), registry)
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+func elem(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+func sortedHash(h fnv64a, hashes []uint64) uint64 {
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	h.writeUint(uint64(len(hashes)))
+	for _, v := range hashes {
+		h.writeUint(v)
+	}
+	return uint64(h)
+}
+
+// fnv64a is the state of a 64 bit FNV-1a hash, kept in a value so hashing a value allocates
+// neither a hasher nor a buffer for the bytes written to it.
+type fnv64a uint64
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+func newFnv() fnv64a {
+	return fnvOffset64
+}
+
+func (this *fnv64a) writeByte(b byte) {
+	*this ^= fnv64a(b)
+	*this *= fnvPrime64
+}
+
+// writeUint writes the big endian bytes of v.
+func (this *fnv64a) writeUint(v uint64) {
+	for shift := 56; shift >= 0; shift -= 8 {
+		this.writeByte(byte(v >> uint(shift)))
+	}
+}
+
+func (this *fnv64a) writeString(s string) {
+	this.writeUint(uint64(len(s)))
+	for i := 0; i < len(s); i++ {
+		this.writeByte(s[i])
+	}
+}
@@ -1,7 +1,9 @@
 package helping
 
 import (
+	"errors"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/saichler/l8types/go/ifs"
@@ -50,19 +52,64 @@ func IgnoreName(fieldName string) bool {
 
 func PropertyNodeKey(instanceId string) string {
 	buff := strings2.New()
-	open := false
-	for _, c := range instanceId {
-		if c == '<' {
-			open = true
-		} else if c == '>' {
-			open = false
-		} else if !open {
-			buff.Add(string(c))
+	for i := 0; i < len(instanceId); i++ {
+		if instanceId[i] == '<' {
+			end := KeyEnd(instanceId, i)
+			if end == -1 {
+				break
+			}
+			i = end
+		} else {
+			buff.Add(instanceId[i : i+1])
 		}
 	}
 	return buff.String()
 }
 
+// KeyEnd returns the index of the '>' closing the key that starts with the '<' at start, or -1 if there is none.
+// A backslash escapes the next character of a key. A predicate key, starting with '?', may have quoted strings
+// and comparisons, so it ends with a '>' that is followed by a '.' or by the end of the id.
+func KeyEnd(id string, start int) int {
+	predicate := start+1 < len(id) && id[start+1] == '?'
+	quoted := false
+	for i := start + 1; i < len(id); i++ {
+		switch {
+		case id[i] == '\\' && (quoted || !predicate):
+			i++
+		case predicate && id[i] == '"':
+			quoted = !quoted
+		case !predicate && id[i] == '>':
+			return i
+		case predicate && !quoted && id[i] == '>' && (i+1 == len(id) || id[i+1] == '.'):
+			return i
+		}
+	}
+	return -1
+}
+
+var keyEscaper = strings.NewReplacer("\\", "\\\\", "<", "\\<", ">", "\\>", "|", "\\|")
+
+// EscapeKey escapes the backslash, '<', '>' and '|' of a key string, so it can be a key of a property id.
+func EscapeKey(key string) string {
+	return keyEscaper.Replace(key)
+}
+
+// KeyString is the key as it is in a property id, its string with the type prefix, escaped.
+func KeyString(key interface{}) string {
+	keyStr := strings2.New()
+	keyStr.TypesPrefix = true
+	return EscapeKey(keyStr.StringOf(key))
+}
+
+// KeyId is the property id of the map entry or slice element at the key, e.g. "device.interfaces<{24}eth0>".
+// An empty id, of a value without a property id, stays empty.
+func KeyId(id string, key interface{}) string {
+	if id == "" {
+		return ""
+	}
+	return id + "<" + KeyString(key) + ">"
+}
+
 func NodeCacheKey(node *l8reflect.L8Node) string {
 	if node.CachedKey != "" {
 		return node.CachedKey
@@ -101,3 +148,101 @@ func PrimaryDecoratorFields(node *l8reflect.L8Node, registry ifs.IRegistry) []st
 	}
 	return fields
 }
+
+// PrimaryKeyIndex returns the index of the slice element with the given primary key, or -1 if there is none.
+func PrimaryKeyIndex(node *l8reflect.L8Node, slice reflect.Value, key interface{}, registry ifs.IRegistry) int {
+	if !slice.IsValid() || slice.IsNil() {
+		return -1
+	}
+	for i := 0; i < slice.Len(); i++ {
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		if PrimaryDecorator(node, elem, registry) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// The decorator types of this module are not in the L8DecoratorType enum generated in l8types,
+// so they are all defined here, numbered from 100 to stay away from the enum values.
+const (
+	//SensitiveDecorator marks a node holding a secret, e.g. a password, so clones and change logs can mask it.
+	SensitiveDecorator l8reflect.L8DecoratorType = iota + 100
+	//JsonNameDecorator and ProtoNameDecorator are the json and protobuf names of a struct field node, from its tags.
+	JsonNameDecorator
+	ProtoNameDecorator
+)
+
+func IsSensitive(node *l8reflect.L8Node) bool {
+	if node == nil || node.Decorators == nil {
+		return false
+	}
+	_, ok := node.Decorators[int32(SensitiveDecorator)]
+	return ok
+}
+
+// DecoratorName returns the name of the node in the name decorator, or "" if it has none.
+func DecoratorName(node *l8reflect.L8Node, decorator l8reflect.L8DecoratorType) string {
+	if node == nil || node.Decorators == nil {
+		return ""
+	}
+	decValue, ok := node.Decorators[int32(decorator)]
+	if !ok {
+		return ""
+	}
+	v, _ := strings2.InstanceOf(decValue, nil)
+	name, _ := v.(string)
+	return name
+}
+
+// AttributeByName returns the attribute of the node whose field name, json name or protobuf name is the name,
+// or nil if there is none. The field names, as is or lowercased as in a property id, are matched first, then the exact
+// json and protobuf names and only then the names ignoring case. The field names are matched in their sorted order,
+// so the attribute does not depend on the order of the attributes map.
+func AttributeByName(node *l8reflect.L8Node, name string) *l8reflect.L8Node {
+	if node == nil || node.Attributes == nil || name == "" {
+		return nil
+	}
+	fieldNames := make([]string, 0, len(node.Attributes))
+	for fieldName := range node.Attributes {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+	jsonName := func(fieldName string) string { return DecoratorName(node.Attributes[fieldName], JsonNameDecorator) }
+	protoName := func(fieldName string) string { return DecoratorName(node.Attributes[fieldName], ProtoNameDecorator) }
+	matchers := []func(fieldName string) bool{
+		func(fieldName string) bool { return fieldName == name || strings.ToLower(fieldName) == name },
+		func(fieldName string) bool { return jsonName(fieldName) == name },
+		func(fieldName string) bool { return protoName(fieldName) == name },
+		func(fieldName string) bool { return strings.EqualFold(fieldName, name) },
+		func(fieldName string) bool { return strings.EqualFold(jsonName(fieldName), name) },
+		func(fieldName string) bool { return strings.EqualFold(protoName(fieldName), name) },
+	}
+	for _, matches := range matchers {
+		for _, fieldName := range fieldNames {
+			if matches(fieldName) {
+				return node.Attributes[fieldName]
+			}
+		}
+	}
+	return nil
+}
+
+// As returns the value as T, or an error if it is not a T. A nil value is returned as the zero T.
+func As[T any](value interface{}) (T, error) {
+	var zero T
+	if value == nil {
+		return zero, nil
+	}
+	t, ok := value.(T)
+	if !ok {
+		return zero, errors.New("Value of type " + reflect.TypeOf(value).String() + " is not " + reflect.TypeOf(&zero).Elem().String())
+	}
+	return t, nil
+}
@@ -2,6 +2,7 @@ package introspecting
 
 import (
 	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
 	"github.com/saichler/l8utils/go/utils/strings"
 )
 
@@ -37,9 +38,20 @@ func AddNoNestedInspection(rnode *l8reflect.L8Node) {
 }
 
 func NoNestedInspection(rnode *l8reflect.L8Node) bool {
+	if rnode == nil || rnode.Decorators == nil {
+		return false
+	}
+	_, ok := rnode.Decorators[int32(l8reflect.L8DecoratorType_NoNestedInspection)]
+	if !ok {
+		return false
+	}
 	dec, _ := decoratorOf(l8reflect.L8DecoratorType_NoNestedInspection, rnode).(string)
 	if dec != "" {
 		return true
 	}
 	return false
 }
+
+func AddSensitiveDecorator(rnode *l8reflect.L8Node) {
+	addDecorator(helping.SensitiveDecorator, "t", rnode)
+}
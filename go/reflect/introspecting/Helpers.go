@@ -2,6 +2,7 @@ package introspecting
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/saichler/l8types/go/types/l8reflect"
 	"github.com/saichler/l8reflect/go/reflect/helping"
@@ -39,7 +40,8 @@ func (this *Introspector) fixClone(clone *l8reflect.L8Node, parent *l8reflect.L8
 
 func (this *Introspector) addNode(_type reflect.Type, _parent *l8reflect.L8Node, _fieldName string) (*l8reflect.L8Node, bool) {
 	exist, ok := this.typeToNode.Get(_type.Name())
-	if ok && !helping.IsLeaf(exist) {
+	//a NoNestedInspection node is opaque only at its own field, so it is not cloned into other fields
+	if ok && !helping.IsLeaf(exist) && !NoNestedInspection(exist) {
 		clone := this.cloner.Clone(exist).(*l8reflect.L8Node)
 		this.fixClone(clone, _parent, _fieldName)
 		return clone, true
@@ -65,26 +67,80 @@ func (this *Introspector) inspectStruct(_type reflect.Type, _parent *l8reflect.L
 	}
 	localNode.IsStruct = true
 	this.registry.RegisterType(_type)
+	if NoNestedInspection(localNode) {
+		return localNode
+	}
 	for index := 0; index < _type.NumField(); index++ {
 		field := _type.Field(index)
 		if helping.IgnoreName(field.Name) {
 			continue
 		}
+		var subnode *l8reflect.L8Node
 		if field.Type.Kind() == reflect.Slice {
-			this.inspectSlice(field.Type, localNode, field.Name)
+			subnode = this.inspectSlice(field.Type, localNode, field.Name)
 		} else if field.Type.Kind() == reflect.Map {
-			this.inspectMap(field.Type, localNode, field.Name)
+			subnode = this.inspectMap(field.Type, localNode, field.Name)
 		} else if field.Type.Kind() == reflect.Ptr {
-			subnode := this.inspectPtr(field.Type.Elem(), localNode, field.Name)
+			subnode = this.inspectPtr(field.Type.Elem(), localNode, field.Name)
 			this.typeToNode.Put(subnode.TypeName, subnode)
 		} else {
-			this.addNode(field.Type, localNode, field.Name)
+			subnode, _ = this.addNode(field.Type, localNode, field.Name)
 		}
+		addFieldNames(subnode, field)
 	}
 	this.addTableView(localNode)
 	return localNode
 }
 
+// addFieldNames decorates the node with the json and protobuf names of its field, a protobuf "json=" name
+// is the json name as the field json tag is its protobuf name.
+// A node cloned from the node of another field has its names, so they are replaced.
+func addFieldNames(node *l8reflect.L8Node, field reflect.StructField) {
+	if node == nil {
+		return
+	}
+	if node.Decorators != nil {
+		delete(node.Decorators, int32(helping.JsonNameDecorator))
+		delete(node.Decorators, int32(helping.ProtoNameDecorator))
+	}
+	jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+	protoName := jsonName
+	for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			protoName = part[len("name="):]
+		} else if strings.HasPrefix(part, "json=") {
+			jsonName = part[len("json="):]
+		}
+	}
+	if jsonName != "" && jsonName != "-" {
+		addDecorator(helping.JsonNameDecorator, jsonName, node)
+	}
+	if protoName != "" && protoName != "-" {
+		addDecorator(helping.ProtoNameDecorator, protoName, node)
+	}
+}
+
+// opaqueParent returns the closest parent of the node decorated with NoNestedInspection.
+func opaqueParent(node *l8reflect.L8Node) *l8reflect.L8Node {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		if NoNestedInspection(parent) {
+			return parent
+		}
+	}
+	return nil
+}
+
+func (this *Introspector) pruneNested(node *l8reflect.L8Node) {
+	if node.Attributes == nil {
+		return
+	}
+	for _, attr := range node.Attributes {
+		this.pruneNested(attr)
+		this.pathToNode.Del(helping.NodeCacheKey(attr))
+	}
+	node.Attributes = nil
+}
+
 func (this *Introspector) inspectPtr(_type reflect.Type, _parent *l8reflect.L8Node, _fieldName string) *l8reflect.L8Node {
 	switch _type.Kind() {
 	case reflect.Struct:
@@ -18,6 +18,8 @@ type Introspector struct {
 	registry   ifs.IRegistry
 	cloner     *cloning.Cloner
 	tableViews *maps.SyncMap
+	//redactors are the cloners masking the sensitive nodes of a root type, built when a node is made sensitive
+	redactors *maps.SyncMap
 }
 
 func NewIntrospect(registry ifs.IRegistry) *Introspector {
@@ -27,6 +29,7 @@ func NewIntrospect(registry ifs.IRegistry) *Introspector {
 	instrospector.pathToNode = NewIntrospectNodeMap()
 	instrospector.typeToNode = NewIntrospectNodeMap()
 	instrospector.tableViews = maps.NewSyncMap()
+	instrospector.redactors = maps.NewSyncMap()
 	return instrospector
 }
 
@@ -53,8 +56,19 @@ func (this *Introspector) Inspect(any interface{}) (*l8reflect.L8Node, error) {
 	return this.inspectStruct(t, nil, ""), nil
 }
 
+// Node returns the node of the path, the nested nodes of a node decorated with NoNestedInspection
+// are removed on lookup.
 func (this *Introspector) Node(path string) (*l8reflect.L8Node, bool) {
-	return this.pathToNode.Get(strings.ToLower(path))
+	node, ok := this.pathToNode.Get(strings.ToLower(path))
+	if !ok {
+		return nil, false
+	}
+	opaque := opaqueParent(node)
+	if opaque != nil {
+		this.pruneNested(opaque)
+		return nil, false
+	}
+	return node, true
 }
 
 func (this *Introspector) NodeByValue(any interface{}) (*l8reflect.L8Node, bool) {
@@ -76,7 +90,10 @@ func (this *Introspector) NodeByTypeName(name string) (*l8reflect.L8Node, bool)
 func (this *Introspector) Nodes(onlyLeafs, onlyRoots bool) []*l8reflect.L8Node {
 	filter := func(any interface{}) bool {
 		n := any.(*l8reflect.L8Node)
-		if onlyLeafs && !helping.IsLeaf(n) {
+		if opaqueParent(n) != nil {
+			return false
+		}
+		if onlyLeafs && !helping.IsLeaf(n) && !NoNestedInspection(n) {
 			return false
 		}
 		if onlyRoots && !helping.IsRoot(n) {
@@ -100,6 +117,50 @@ func (this *Introspector) Clone(any interface{}) interface{} {
 	return this.cloner.Clone(any)
 }
 
+// AddNoNestedInspection marks the node as an opaque leaf, removing its nested nodes.
+// When shareOnClone is true, the values at the node path are shared instead of deep copied by Clone,
+// other fields of the same type are still deep copied.
+func (this *Introspector) AddNoNestedInspection(node *l8reflect.L8Node, shareOnClone bool) {
+	AddNoNestedInspection(node)
+	this.pruneNested(node)
+	if node.Parent != nil {
+		this.addTableView(node.Parent)
+	}
+	if shareOnClone {
+		this.cloner.AddHook(helping.NodeCacheKey(node), &cloning.CloneHook{Action: cloning.HookShare})
+	}
+}
+
+// AddSensitiveDecorator marks the node as sensitive and rebuilds the redactor of its root type,
+// so the change logs mask the values of the sensitive nodes.
+func (this *Introspector) AddSensitiveDecorator(node *l8reflect.L8Node) error {
+	AddSensitiveDecorator(node)
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	redactor := cloning.NewCloner()
+	err := redactor.AddSensitiveHooks(root, &cloning.CloneHook{Action: cloning.HookMask})
+	if err != nil {
+		return err
+	}
+	this.redactors.Put(root.TypeName, redactor)
+	return nil
+}
+
+// Redactor returns the cloner masking the sensitive nodes of the node root type, or nil if none was made sensitive.
+func (this *Introspector) Redactor(node *l8reflect.L8Node) *cloning.Cloner {
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	redactor, ok := this.redactors.Get(root.TypeName)
+	if !ok {
+		return nil
+	}
+	return redactor.(*cloning.Cloner)
+}
+
 func (this *Introspector) addTableView(node *l8reflect.L8Node) {
 	tv := &l8reflect.L8TableView{Table: node, Columns: make([]*l8reflect.L8Node, 0), SubTables: make([]*l8reflect.L8Node, 0)}
 	for _, attr := range node.Attributes {
@@ -131,6 +192,7 @@ func (this *Introspector) Clean(typeName string) {
 		return
 	}
 	this.clean(node)
+	this.redactors.Delete(typeName)
 }
 
 func (this *Introspector) clean(node *l8reflect.L8Node) {
@@ -0,0 +1,318 @@
+package mapping
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+// Unmapped is a value the Mapper could not copy, or a destination field no source field maps to.
+// PropertyId is the path in the source, with the destination field name for the latter.
+type Unmapped struct {
+	PropertyId string
+	Reason     string
+}
+
+func (this *Unmapped) String() string {
+	return this.PropertyId + ": " + this.Reason
+}
+
+// Mapper copies between two different struct types, matching their fields by a rename table,
+// by a struct tag or by name, and converting the numeric kinds.
+type Mapper struct {
+	resources ifs.IResources
+	cloner    *cloning.Cloner
+	renames   map[string]string
+	tag       string
+}
+
+type mapState struct {
+	//seen maps the source pointers to the destination pointers, so cycles are kept
+	seen     map[mapKey]reflect.Value
+	unmapped []*Unmapped
+}
+
+type mapKey struct {
+	addr    uintptr
+	srcType reflect.Type
+	dstType reflect.Type
+}
+
+func NewMapper(resources ifs.IResources) *Mapper {
+	mapper := &Mapper{}
+	mapper.resources = resources
+	mapper.cloner = cloning.NewCloner()
+	mapper.renames = make(map[string]string)
+	return mapper
+}
+
+// Rename maps the field of the source type name to a destination field with a different name.
+func (this *Mapper) Rename(srcTypeName, srcField, dstField string) {
+	this.renames[srcTypeName+"."+srcField] = dstField
+}
+
+// SetTag sets the struct tag naming the field on the other side, e.g. `map:"FullName"`.
+// The tag can be on either the source or the destination field.
+func (this *Mapper) SetTag(tag string) {
+	this.tag = tag
+}
+
+// Map copies src into dst, which must be a non nil pointer. The values of the same type are deep cloned,
+// structs, slices and maps of different types are mapped recursively. The values that could not be
+// mapped are returned, they are left empty in dst.
+func (this *Mapper) Map(dst, src interface{}) ([]*Unmapped, error) {
+	dstValue := reflect.ValueOf(dst)
+	if !dstValue.IsValid() || dstValue.Kind() != reflect.Ptr || dstValue.IsNil() {
+		return nil, errors.New("map destination must be a non nil pointer")
+	}
+	srcValue := reflect.ValueOf(src)
+	if !srcValue.IsValid() || (srcValue.Kind() == reflect.Ptr && srcValue.IsNil()) {
+		return nil, errors.New("map source is nil")
+	}
+	state := &mapState{seen: make(map[mapKey]reflect.Value), unmapped: make([]*Unmapped, 0)}
+	if srcValue.Kind() == reflect.Ptr {
+		state.seen[mapKey{srcValue.Pointer(), srcValue.Type(), dstValue.Type()}] = dstValue
+		srcValue = srcValue.Elem()
+	}
+	this.mapValue(dstValue.Elem(), srcValue, this.rootId(srcValue), state)
+	return state.unmapped, nil
+}
+
+func (this *Mapper) mapValue(dst, src reflect.Value, id string, state *mapState) {
+	if src.Type() == dst.Type() {
+		cloned := reflect.ValueOf(this.cloner.Clone(src.Interface()))
+		if cloned.IsValid() {
+			dst.Set(cloned)
+		} else {
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return
+	}
+	//an interface is mapped by the value it holds
+	if src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		this.mapValue(dst, src.Elem(), id, state)
+		return
+	}
+	switch {
+	case dst.Kind() == reflect.Interface:
+		if !src.Type().Implements(dst.Type()) {
+			state.unmapped = append(state.unmapped, &Unmapped{PropertyId: id,
+				Reason: "cannot convert " + src.Type().String() + " to " + dst.Type().String()})
+			return
+		}
+		dst.Set(reflect.ValueOf(this.cloner.Clone(src.Interface())))
+	case src.Kind() == reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		if dst.Kind() != reflect.Ptr {
+			this.mapValue(dst, src.Elem(), id, state)
+			return
+		}
+		key := mapKey{src.Pointer(), src.Type(), dst.Type()}
+		exist, ok := state.seen[key]
+		if ok {
+			dst.Set(exist)
+			return
+		}
+		newPtr := reflect.New(dst.Type().Elem())
+		state.seen[key] = newPtr
+		this.mapValue(newPtr.Elem(), src.Elem(), id, state)
+		dst.Set(newPtr)
+	case dst.Kind() == reflect.Ptr:
+		newPtr := reflect.New(dst.Type().Elem())
+		this.mapValue(newPtr.Elem(), src, id, state)
+		dst.Set(newPtr)
+	case src.Kind() == reflect.Struct && dst.Kind() == reflect.Struct:
+		this.mapStruct(dst, src, id, state)
+	case src.Kind() == reflect.Slice && dst.Kind() == reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		newSlice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			this.mapValue(newSlice.Index(i), src.Index(i), helping.KeyId(id, i), state)
+		}
+		dst.Set(newSlice)
+	case src.Kind() == reflect.Map && dst.Kind() == reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		newMap := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			entryId := helping.KeyId(id, iter.Key().Interface())
+			key := reflect.New(dst.Type().Key()).Elem()
+			if !this.mapLeaf(key, iter.Key(), entryId, state) {
+				continue
+			}
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			this.mapValue(elem, iter.Value(), entryId, state)
+			newMap.SetMapIndex(key, elem)
+		}
+		dst.Set(newMap)
+	default:
+		this.mapLeaf(dst, src, id, state)
+	}
+}
+
+// mapLeaf converts a numeric value into another numeric kind, e.g. an int32 into an int64, or a value into a type of the same kind.
+// Any other conversion, e.g. a number into a string, is reported as unmapped.
+func (this *Mapper) mapLeaf(dst, src reflect.Value, id string, state *mapState) bool {
+	if src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return true
+		}
+		src = src.Elem()
+	}
+	converted := src
+	if properties.IsNumeric(src.Kind()) {
+		converted = properties.ConvertValue(dst, src)
+	}
+	if converted.Type().AssignableTo(dst.Type()) {
+		dst.Set(converted)
+		return true
+	}
+	if converted.Kind() == dst.Kind() && converted.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(converted.Convert(dst.Type()))
+		return true
+	}
+	state.unmapped = append(state.unmapped, &Unmapped{PropertyId: id,
+		Reason: "cannot convert " + src.Type().String() + " to " + dst.Type().String()})
+	return false
+}
+
+func (this *Mapper) mapStruct(dst, src reflect.Value, id string, state *mapState) {
+	dstType := dst.Type()
+	srcType := src.Type()
+	mapped := make(map[int]bool)
+	for i := 0; i < srcType.NumField(); i++ {
+		srcField := srcType.Field(i)
+		if cloning.SkipFieldByName(srcField.Name) {
+			continue
+		}
+		fieldId := id + "." + strings.ToLower(srcField.Name)
+		index := this.dstField(srcType, srcField, dstType)
+		if index == -1 {
+			state.unmapped = append(state.unmapped, &Unmapped{PropertyId: fieldId, Reason: "no matching field in " + dstType.Name()})
+			continue
+		}
+		mapped[index] = true
+		this.mapValue(dst.Field(index), src.Field(i), fieldId, state)
+	}
+	for i := 0; i < dstType.NumField(); i++ {
+		if !mapped[i] && !cloning.SkipFieldByName(dstType.Field(i).Name) {
+			state.unmapped = append(state.unmapped, &Unmapped{PropertyId: id + "." + strings.ToLower(dstType.Field(i).Name),
+				Reason: "no matching field in " + srcType.Name()})
+		}
+	}
+}
+
+// dstField returns the index of the destination field the source field maps to, or -1.
+// The rename table comes first, then the struct tags, then the field names ignoring their case
+// and then the json and protobuf names of the fields.
+func (this *Mapper) dstField(srcType reflect.Type, srcField reflect.StructField, dstType reflect.Type) int {
+	name, ok := this.renames[srcType.Name()+"."+srcField.Name]
+	if !ok && this.tag != "" {
+		name = srcField.Tag.Get(this.tag)
+	}
+	if name != "" {
+		field, ok := dstType.FieldByName(name)
+		if ok && len(field.Index) == 1 {
+			return field.Index[0]
+		}
+		return -1
+	}
+	byName := -1
+	for i := 0; i < dstType.NumField(); i++ {
+		dstField := dstType.Field(i)
+		if cloning.SkipFieldByName(dstField.Name) {
+			continue
+		}
+		if this.tag != "" {
+			tag := dstField.Tag.Get(this.tag)
+			if tag == srcField.Name {
+				return i
+			}
+			if tag != "" {
+				continue
+			}
+		}
+		if byName == -1 && strings.EqualFold(dstField.Name, srcField.Name) {
+			byName = i
+		}
+	}
+	if byName != -1 {
+		return byName
+	}
+	return this.dstFieldByNames(srcType, srcField, dstType)
+}
+
+// dstFieldByNames returns the index of the destination field whose field, json or protobuf name is the json
+// or protobuf name of the source field, as decorated on their introspected nodes, or -1.
+func (this *Mapper) dstFieldByNames(srcType reflect.Type, srcField reflect.StructField, dstType reflect.Type) int {
+	srcNode := this.nodeOf(srcType)
+	dstNode := this.nodeOf(dstType)
+	if srcNode == nil || dstNode == nil {
+		return -1
+	}
+	srcAttr := srcNode.Attributes[srcField.Name]
+	for _, decorator := range []l8reflect.L8DecoratorType{helping.JsonNameDecorator, helping.ProtoNameDecorator} {
+		attr := helping.AttributeByName(dstNode, helping.DecoratorName(srcAttr, decorator))
+		if attr == nil {
+			continue
+		}
+		field, ok := dstType.FieldByName(attr.FieldName)
+		//a destination field tagged with another field name is only mapped by its tag
+		if ok && len(field.Index) == 1 && (this.tag == "" || field.Tag.Get(this.tag) == "") {
+			return field.Index[0]
+		}
+	}
+	return -1
+}
+
+// nodeOf returns the node of the struct type, introspecting it on first sight, or nil without resources.
+func (this *Mapper) nodeOf(typ reflect.Type) *l8reflect.L8Node {
+	if this.resources == nil || typ.Name() == "" {
+		return nil
+	}
+	node, ok := this.resources.Introspector().NodeByType(typ)
+	if ok {
+		return node
+	}
+	node, err := this.resources.Introspector().Inspect(reflect.New(typ).Interface())
+	if err != nil {
+		return nil
+	}
+	return node
+}
+
+// rootId is the property id of the source instance, including its primary key when it is introspected.
+func (this *Mapper) rootId(value reflect.Value) string {
+	id := strings.ToLower(value.Type().Name())
+	if this.resources == nil || value.Kind() != reflect.Struct {
+		return id
+	}
+	node, ok := this.resources.Introspector().Node(value.Type().Name())
+	if !ok {
+		return id
+	}
+	key := helping.PrimaryDecorator(node, value, this.resources.Registry())
+	if key == nil {
+		return id
+	}
+	return helping.KeyId(id, key)
+}
@@ -0,0 +1,137 @@
+package properties
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// GetAs returns the values of the property in any as T, a single value is returned as a slice of one.
+// A map or slice property with a key returns the element at the key.
+// A value that is not a T is returned as an error, missing values, e.g. of a key not in a map, are skipped.
+func GetAs[T any](property *Property, any interface{}) ([]T, error) {
+	if property == nil {
+		return nil, errors.New("property is nil")
+	}
+	if any == nil {
+		return nil, errors.New("cannot get " + propertyIdOf(property) + " from a nil instance")
+	}
+	err := property.checkInstance(any)
+	if err != nil {
+		return nil, err
+	}
+	values := property.GetValue(reflect.ValueOf(any))
+	result := make([]T, 0, len(values))
+	for _, value := range values {
+		if property.key != nil && value.IsValid() && (value.Kind() == reflect.Map || value.Kind() == reflect.Slice) {
+			value = property.elementOf(value)
+		}
+		if !value.IsValid() {
+			continue
+		}
+		t, err := helping.As[T](value.Interface())
+		if err != nil {
+			return nil, errors.New(propertyIdOf(property) + ": " + err.Error())
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// elementOf returns the element of the map or slice at the property key, or an invalid value if there is none.
+func (this *Property) elementOf(value reflect.Value) reflect.Value {
+	if value.Kind() == reflect.Map {
+		return value.MapIndex(reflect.ValueOf(this.key))
+	}
+	index, ok := this.key.(int)
+	if !ok {
+		index = helping.PrimaryKeyIndex(this.node, value, this.key, this.resources.Registry())
+	}
+	if index < 0 || index >= value.Len() {
+		return reflect.Value{}
+	}
+	return value.Index(index)
+}
+
+// SetAs sets the value of the property in any, reporting a value that does not fit the property as an error.
+func SetAs[T any](property *Property, any interface{}, value T) error {
+	if property == nil {
+		return errors.New("property is nil")
+	}
+	if any == nil {
+		return errors.New("cannot set " + propertyIdOf(property) + " in a nil instance")
+	}
+	err := property.checkInstance(any)
+	if err != nil {
+		return err
+	}
+	valueType, err := property.valueType()
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(value) != nil && !reflect.TypeOf(value).AssignableTo(valueType) {
+		return errors.New("Invalid value type " + reflect.TypeOf(value).String() + " for PID: " + propertyIdOf(property) +
+			", expected " + valueType.String())
+	}
+	_, _, err = property.Set(any, value)
+	return err
+}
+
+// valueType returns the type of the property values, the element type for a map or slice property with a key.
+func (this *Property) valueType() (reflect.Type, error) {
+	if this.parent == nil {
+		info, err := this.resources.Registry().Info(this.node.TypeName)
+		if err != nil {
+			return nil, err
+		}
+		return info.Type(), nil
+	}
+	parentType, err := this.parent.valueType()
+	if err != nil {
+		return nil, err
+	}
+	for parentType.Kind() == reflect.Ptr {
+		parentType = parentType.Elem()
+	}
+	field, ok := parentType.FieldByName(this.node.FieldName)
+	if !ok {
+		return nil, errors.New("No field " + this.node.FieldName + " in " + parentType.String())
+	}
+	if this.key != nil && (field.Type.Kind() == reflect.Map || field.Type.Kind() == reflect.Slice) {
+		return field.Type.Elem(), nil
+	}
+	return field.Type, nil
+}
+
+// checkInstance returns an error if any is not an instance, or a pointer to an instance, of the property root type.
+func (this *Property) checkInstance(any interface{}) error {
+	root := this
+	for root.parent != nil {
+		root = root.parent
+	}
+	rootType, err := root.valueType()
+	if err != nil {
+		return err
+	}
+	for rootType.Kind() == reflect.Ptr {
+		rootType = rootType.Elem()
+	}
+	instanceType := reflect.TypeOf(any)
+	for instanceType.Kind() == reflect.Ptr {
+		instanceType = instanceType.Elem()
+	}
+	if instanceType != rootType {
+		return errors.New("Invalid instance type " + reflect.TypeOf(any).String() + " for PID: " + propertyIdOf(this) +
+			", expected " + rootType.String())
+	}
+	return nil
+}
+
+func propertyIdOf(property *Property) string {
+	id, err := property.PropertyId()
+	if err != nil {
+		return property.node.FieldName
+	}
+	return id
+}
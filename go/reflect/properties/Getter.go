@@ -2,6 +2,8 @@ package properties
 
 import (
 	"reflect"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
 )
 
 func (this *Property) getMap(parent reflect.Value) []reflect.Value {
@@ -36,7 +38,15 @@ func (this *Property) getMap(parent reflect.Value) []reflect.Value {
 func (this *Property) getSlice(parent reflect.Value) []reflect.Value {
 	result := make([]reflect.Value, 0)
 	if this.parent.key != nil {
-		myValue := parent.Index(this.parent.key.(int))
+		index, ok := this.parent.key.(int)
+		if !ok {
+			//the slice element is identified by its primary key
+			index = helping.PrimaryKeyIndex(this.parent.node, parent, this.parent.key, this.resources.Registry())
+		}
+		if index < 0 || index >= parent.Len() {
+			return result
+		}
+		myValue := parent.Index(index)
 		if !myValue.IsValid() {
 			return result
 		}
@@ -78,12 +88,29 @@ func (this *Property) GetValue(any reflect.Value) []reflect.Value {
 	if this.parent == nil {
 		return []reflect.Value{any}
 	}
+	if this.IsPattern() {
+		matches := this.matches(any)
+		results := make([]reflect.Value, len(matches))
+		for i, m := range matches {
+			results[i] = m.value
+		}
+		return results
+	}
 
 	parents := this.parent.GetValue(any)
 	results := make([]reflect.Value, 0)
 
 	for _, parent := range parents {
+		if parent.Kind() == reflect.Interface {
+			if parent.IsNil() {
+				continue
+			}
+			parent = parent.Elem()
+		}
 		if parent.Kind() == reflect.Ptr {
+			if parent.IsNil() {
+				continue
+			}
 			parent = parent.Elem()
 		}
 		if parent.Kind() == reflect.Map {
@@ -121,6 +148,10 @@ func (this *Property) Get(any interface{}) (interface{}, error) {
 		}
 		return n, nil
 	}
+	//a property id with wildcards, key lists or predicates may match many values, each is returned with its property id
+	if this.IsPattern() {
+		return this.Matches(any)
+	}
 	values := this.GetValue(reflect.ValueOf(any))
 	if !values[0].IsValid() {
 		return nil, nil
@@ -143,6 +174,13 @@ func (this *Property) GetAsValues(any interface{}) []reflect.Value {
 	if _interface == nil {
 		return []reflect.Value{reflect.ValueOf(this)}
 	}
+	if matches, ok := _interface.([]*Match); ok {
+		result := make([]reflect.Value, len(matches))
+		for i, m := range matches {
+			result[i] = reflect.ValueOf(m.Value)
+		}
+		return result
+	}
 	value := reflect.ValueOf(_interface)
 	if value.Kind() == reflect.Map {
 		result := make([]reflect.Value, value.Len())
@@ -0,0 +1,84 @@
+package properties
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// MoveTo is set as the value of a slice element, by its primary key or its index, to move the element to the given index.
+type MoveTo int
+
+var moveToType = reflect.TypeOf(MoveTo(0))
+
+// keyedSliceSet sets a slice element that is identified by its primary key rather than by its index.
+func (this *Property) keyedSliceSet(myValue reflect.Value, newValue reflect.Value) (interface{}, error) {
+	if !myValue.IsValid() || myValue.IsNil() {
+		myValue.Set(reflect.MakeSlice(myValue.Type(), 0, 0))
+	}
+	index := helping.PrimaryKeyIndex(this.node, myValue, this.key, this.resources.Registry())
+
+	//The element was marked for deletion so remove it from the slice
+	if this.isLeaf && (isEntryDelete(newValue) || isDeletedEntry(newValue)) {
+		if index != -1 {
+			newSlice := reflect.MakeSlice(myValue.Type(), 0, myValue.Len()-1)
+			newSlice = reflect.AppendSlice(newSlice, myValue.Slice(0, index))
+			newSlice = reflect.AppendSlice(newSlice, myValue.Slice(index+1, myValue.Len()))
+			myValue.Set(newSlice)
+		}
+		return myValue.Interface(), nil
+	}
+
+	if this.isLeaf && newValue.IsValid() && newValue.Type() == moveToType {
+		to := int(newValue.Int())
+		if index == -1 || to < 0 || to >= myValue.Len() {
+			pid, _ := this.PropertyId()
+			return nil, errors.New("Cannot move " + pid + " to index " + strconv.Itoa(to))
+		}
+		myValue.Set(moveElement(myValue, index, to))
+		return myValue.Interface(), nil
+	}
+
+	//If this is not a leaf property, we need to continue drilling down to the element
+	if !this.isLeaf {
+		if index != -1 {
+			return myValue.Index(index).Interface(), nil
+		}
+		info, err := this.resources.Registry().Info(this.node.TypeName)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := info.NewInstance()
+		if err != nil {
+			return nil, err
+		}
+		this.SetPrimaryKey(this.node, elem, this.key)
+		myValue.Set(reflect.Append(myValue, reflect.ValueOf(elem)))
+		return elem, nil
+	}
+
+	if !newValue.IsValid() || !newValue.Type().AssignableTo(myValue.Type().Elem()) {
+		pid, _ := this.PropertyId()
+		return nil, errors.New("Invalid element value for PID: " + pid)
+	}
+	if index == -1 {
+		myValue.Set(reflect.Append(myValue, newValue))
+	} else {
+		myValue.Index(index).Set(newValue)
+	}
+	return myValue.Interface(), nil
+}
+
+// moveElement returns a new slice with the element at index from moved to index to.
+func moveElement(slice reflect.Value, from, to int) reflect.Value {
+	elem := slice.Index(from)
+	newSlice := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	newSlice = reflect.AppendSlice(newSlice, slice.Slice(0, from))
+	newSlice = reflect.AppendSlice(newSlice, slice.Slice(from+1, slice.Len()))
+	tail := reflect.MakeSlice(slice.Type(), 0, slice.Len()-to)
+	tail = reflect.AppendSlice(tail, newSlice.Slice(to, newSlice.Len()))
+	newSlice = reflect.Append(newSlice.Slice(0, to), elem)
+	return reflect.AppendSlice(newSlice, tail)
+}
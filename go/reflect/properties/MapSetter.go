@@ -51,7 +51,7 @@ func (this *Property) mapSet(myMapValue reflect.Value, newMapValue reflect.Value
 	nKeyValue := newMapValue
 
 	//This map entry was marked for deletion so delete it
-	if this.isLeaf && nKeyValue.Kind() == reflect.String && nKeyValue.String() == ifs.Deleted_Entry {
+	if this.isLeaf && (isEntryDelete(nKeyValue) || isDeletedEntry(nKeyValue)) {
 		myMapValue.SetMapIndex(mapKey, reflect.Value{})
 		return myMapValue.Interface(), err
 	}
@@ -0,0 +1,159 @@
+package properties
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// EntryDelete is set as the value of a map entry or a slice element to delete it.
+// A slice element at an index is removed, shifting the following elements.
+type EntryDelete struct{}
+
+var entryDeleteType = reflect.TypeOf(EntryDelete{})
+
+func isEntryDelete(value reflect.Value) bool {
+	return value.IsValid() && value.Type() == entryDeleteType
+}
+
+// isDeletedEntry returns true for the ifs.Deleted_Entry string, which deleted an entry before EntryDelete
+// and is still accepted as an input.
+func isDeletedEntry(value reflect.Value) bool {
+	return value.IsValid() && value.Kind() == reflect.String && value.String() == ifs.Deleted_Entry
+}
+
+// The reserved strings of the operation values when they are serialized, EntryDelete is ifs.Deleted_Entry.
+const (
+	SliceRemoveEntry = "__SLICE_REMOVE__"
+	SliceInsertEntry = "__SLICE_INSERT__"
+	MoveToEntry      = "__MOVE_TO__:"
+)
+
+// EncodeValue returns a change value in a form any serializer of the change values can serialize.
+// EntryDelete, SliceRemove and MoveTo are encoded as reserved strings and SliceInsert as a []interface{} of
+// SliceInsertEntry and the inserted value. Any other value is returned as is.
+func EncodeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case EntryDelete:
+		return ifs.Deleted_Entry
+	case SliceRemove:
+		return SliceRemoveEntry
+	case MoveTo:
+		return MoveToEntry + strconv.Itoa(int(v))
+	case SliceInsert:
+		return []interface{}{SliceInsertEntry, v.Value}
+	}
+	return value
+}
+
+// DecodeValue returns the operation value of a value encoded by EncodeValue, or the value as is.
+func DecodeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == ifs.Deleted_Entry {
+			return EntryDelete{}
+		}
+		if v == SliceRemoveEntry {
+			return SliceRemove{}
+		}
+		if strings.HasPrefix(v, MoveToEntry) {
+			index, err := strconv.Atoi(v[len(MoveToEntry):])
+			if err == nil {
+				return MoveTo(index)
+			}
+		}
+	case []interface{}:
+		if len(v) == 2 && v[0] == SliceInsertEntry {
+			return SliceInsert{Value: v[1]}
+		}
+	}
+	return value
+}
+
+// Delete deletes the map entry or the slice element of the property, e.g. "networkdevice.interfaces<{24}eth0>".
+// Deleting an entry that does not exist does nothing, except for a slice index which is an error.
+func (this *Property) Delete(root interface{}) error {
+	pid, _ := this.PropertyId()
+	if this.parent == nil || this.key == nil || (!this.node.IsMap && !this.node.IsSlice) {
+		return errors.New("Cannot delete " + pid + ", it is not a map entry or a slice element")
+	}
+	container, err := this.container(root)
+	if err != nil {
+		return err
+	}
+	if !container.IsValid() || container.IsNil() {
+		if _, ok := this.key.(int); ok {
+			return errors.New("Cannot remove index " + strconv.Itoa(this.key.(int)) + " from " + pid)
+		}
+		return nil
+	}
+	_, _, err = this.Set(root, EntryDelete{})
+	return err
+}
+
+// Insert inserts the value at the index of the slice of the property, e.g. "networkdevice.ports",
+// shifting the following elements. An element of a primary keyed slice must have a new primary key.
+func (this *Property) Insert(root interface{}, index int, value interface{}) error {
+	pid, _ := this.PropertyId()
+	if this.parent == nil || !this.node.IsSlice || this.key != nil {
+		return errors.New("Cannot insert into " + pid + ", it is not a slice")
+	}
+	container, err := this.container(root)
+	if err != nil {
+		return err
+	}
+	if container.IsValid() && !container.IsNil() && value != nil {
+		elem := reflect.ValueOf(value)
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if helping.PrimaryDecoratorFields(this.node, this.resources.Registry()) != nil && elem.Kind() == reflect.Struct {
+			key := helping.PrimaryDecorator(this.node, elem, this.resources.Registry())
+			if helping.PrimaryKeyIndex(this.node, container, key, this.resources.Registry()) != -1 {
+				str := keyString(key)
+				return errors.New("Cannot insert into " + pid + ", it already has an element with key " + str)
+			}
+		}
+	}
+	element := NewProperty(this.node, this.parent, index, nil, this.resources)
+	_, _, err = element.Set(root, SliceInsert{Value: value})
+	return err
+}
+
+// Append appends the value to the end of the slice of the property, e.g. "networkdevice.ports".
+func (this *Property) Append(root interface{}, value interface{}) error {
+	container, err := this.container(root)
+	if err != nil {
+		return err
+	}
+	size := 0
+	if container.IsValid() {
+		size = container.Len()
+	}
+	return this.Insert(root, size, value)
+}
+
+// container is the map or the slice holding the entry or the element of the property, if it exists.
+func (this *Property) container(root interface{}) (reflect.Value, error) {
+	if root == nil {
+		pid, _ := this.PropertyId()
+		return reflect.Value{}, errors.New("Cannot modify " + pid + " of a nil instance")
+	}
+	if this.IsPattern() {
+		pid, _ := this.PropertyId()
+		return reflect.Value{}, errors.New("Cannot modify a property id with a wildcard, a key list or a predicate: " + pid)
+	}
+	values := this.GetValue(reflect.ValueOf(root))
+	if len(values) != 1 {
+		return reflect.Value{}, nil
+	}
+	value := values[0]
+	if value.Kind() != reflect.Map && value.Kind() != reflect.Slice {
+		return reflect.Value{}, nil
+	}
+	return value, nil
+}
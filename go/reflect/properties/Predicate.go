@@ -0,0 +1,357 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// Predicate is the key of a property id segment that filters the map or slice elements by their attributes,
+// e.g. `networkdevice.interfaces<?adminstatus=="up" && mtu>=1500>.name`.
+// It supports ==, !=, <, <=, >, >=, in (...), &&, ||, ! and parentheses. String literals are double quoted.
+type Predicate struct {
+	source string
+	expr   predicateExpr
+}
+
+type predicateExpr interface {
+	eval(element reflect.Value) bool
+}
+
+type predicateAnd struct{ left, right predicateExpr }
+type predicateOr struct{ left, right predicateExpr }
+type predicateNot struct{ expr predicateExpr }
+
+// predicateComp compares the attribute at the path of the element with the values, more than one value is an "in".
+type predicateComp struct {
+	path   []string
+	op     string
+	values []reflect.Value
+}
+
+func (this *predicateAnd) eval(element reflect.Value) bool {
+	return this.left.eval(element) && this.right.eval(element)
+}
+
+func (this *predicateOr) eval(element reflect.Value) bool {
+	return this.left.eval(element) || this.right.eval(element)
+}
+
+func (this *predicateNot) eval(element reflect.Value) bool {
+	return !this.expr.eval(element)
+}
+
+func (this *predicateComp) eval(element reflect.Value) bool {
+	value := element
+	for _, field := range this.path {
+		value = elem(value)
+		if value.Kind() != reflect.Struct {
+			return false
+		}
+		value = value.FieldByName(field)
+	}
+	if !value.IsValid() {
+		return false
+	}
+	if this.op == "in" {
+		for _, v := range this.values {
+			if compareValues(value, v) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	c := compareValues(value, this.values[0])
+	switch this.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c == -1
+	case "<=":
+		return c == -1 || c == 0
+	case ">":
+		return c == 1
+	case ">=":
+		return c == 1 || c == 0
+	}
+	return false
+}
+
+// compareValues returns -1, 0 or 1, or 2 if the values are not comparable.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.String:
+		return compareOrdered(a.String(), b.String())
+	case reflect.Bool:
+		if a.Bool() == b.Bool() {
+			return 0
+		}
+	}
+	return 2
+}
+
+func compareOrdered[T int64 | uint64 | float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// parsePredicate parses the predicate of the elements of the node, converting its literals
+// to the types of the attributes they are compared with.
+// The id and the column of the predicate key are for positioned errors.
+func parsePredicate(source string, node *l8reflect.L8Node, property *Property, id string, column int) (Predicate, error) {
+	parser := &predicateParser{source: source, node: node, property: property, id: id, column: column}
+	parser.tokenize()
+	if parser.err != nil {
+		return Predicate{}, parser.err
+	}
+	expr := parser.parseOr()
+	if parser.err == nil && parser.pos < len(parser.tokens) {
+		parser.pos++
+		parser.fail("unexpected " + parser.tokens[parser.pos-1])
+	}
+	if parser.err != nil {
+		return Predicate{}, parser.err
+	}
+	return Predicate{source: source, expr: expr}, nil
+}
+
+type predicateParser struct {
+	source   string
+	tokens   []string
+	offsets  []int
+	pos      int
+	id       string
+	column   int
+	node     *l8reflect.L8Node
+	property *Property
+	err      error
+}
+
+// fail sets the error at the last read token.
+func (this *predicateParser) fail(msg string) {
+	if this.err != nil {
+		return
+	}
+	offset := len(this.source)
+	if this.pos > 0 && this.pos <= len(this.offsets) {
+		offset = this.offsets[this.pos-1]
+	}
+	this.err = &PathError{Id: this.id, Column: this.column + 1 + offset, Reason: "invalid predicate " + this.source + ": " + msg}
+}
+
+func (this *predicateParser) add(start, end int) {
+	this.tokens = append(this.tokens, this.source[start:end])
+	this.offsets = append(this.offsets, start)
+}
+
+func (this *predicateParser) tokenize() {
+	s := this.source
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ':
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				this.add(i, len(s))
+				this.pos = len(this.tokens)
+				this.fail("unterminated string")
+				return
+			}
+			this.add(i, j+1)
+			i = j + 1
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			j := i + 1
+			for j < len(s) && strings.ContainsRune("=&|", rune(s[j])) {
+				j++
+			}
+			this.add(i, j)
+			i = j
+		case strings.ContainsRune("(),", rune(c)):
+			this.add(i, i+1)
+			i++
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \"=!<>&|(),", rune(s[j])) {
+				j++
+			}
+			this.add(i, j)
+			i = j
+		}
+	}
+}
+
+func (this *predicateParser) peek() string {
+	if this.pos < len(this.tokens) {
+		return this.tokens[this.pos]
+	}
+	return ""
+}
+
+func (this *predicateParser) next() string {
+	token := this.peek()
+	this.pos++
+	if token == "" {
+		this.fail("unexpected end")
+	}
+	return token
+}
+
+func (this *predicateParser) parseOr() predicateExpr {
+	left := this.parseAnd()
+	for this.err == nil && this.peek() == "||" {
+		this.pos++
+		left = &predicateOr{left: left, right: this.parseAnd()}
+	}
+	return left
+}
+
+func (this *predicateParser) parseAnd() predicateExpr {
+	left := this.parseUnary()
+	for this.err == nil && this.peek() == "&&" {
+		this.pos++
+		left = &predicateAnd{left: left, right: this.parseUnary()}
+	}
+	return left
+}
+
+func (this *predicateParser) parseUnary() predicateExpr {
+	switch this.peek() {
+	case "!":
+		this.pos++
+		return &predicateNot{expr: this.parseUnary()}
+	case "(":
+		this.pos++
+		expr := this.parseOr()
+		if this.next() != ")" {
+			this.fail("missing )")
+		}
+		return expr
+	}
+	return this.parseComp()
+}
+
+func (this *predicateParser) parseComp() predicateExpr {
+	comp := &predicateComp{}
+	attr := this.parsePath(this.next(), comp)
+	comp.op = this.next()
+	if this.err != nil {
+		return comp
+	}
+	switch comp.op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		comp.values = append(comp.values, this.parseLiteral(this.next(), attr))
+	case "in":
+		if this.next() != "(" {
+			this.fail("expected ( after in")
+			return comp
+		}
+		for this.err == nil {
+			comp.values = append(comp.values, this.parseLiteral(this.next(), attr))
+			token := this.next()
+			if token == ")" {
+				break
+			}
+			if token != "," {
+				this.fail("expected , or ) in the in list")
+			}
+		}
+	default:
+		this.fail("unknown operator " + comp.op)
+	}
+	return comp
+}
+
+// parsePath resolves the attribute path, relative to the elements, to its field names and returns its node.
+func (this *predicateParser) parsePath(path string, comp *predicateComp) *l8reflect.L8Node {
+	node := this.node
+	for _, name := range strings.Split(path, ".") {
+		attr := helping.AttributeByName(node, name)
+		if attr == nil || attr.IsMap || attr.IsSlice {
+			this.fail("unknown attribute " + path)
+			return node
+		}
+		comp.path = append(comp.path, attr.FieldName)
+		node = attr
+	}
+	return node
+}
+
+// parseLiteral converts the literal to the type of the attribute, by its type name in the registry.
+func (this *predicateParser) parseLiteral(literal string, attr *l8reflect.L8Node) reflect.Value {
+	if this.err != nil {
+		return reflect.Value{}
+	}
+	info, err := this.property.resources.Registry().Info(attr.TypeName)
+	if err != nil {
+		this.fail(err.Error())
+		return reflect.Value{}
+	}
+	typ := info.Type()
+	target := reflect.New(typ).Elem()
+	if strings.HasPrefix(literal, "\"") {
+		str, err := strconv.Unquote(literal)
+		if err != nil {
+			this.fail("invalid string " + literal)
+			return target
+		}
+		if typ.Kind() == reflect.String {
+			return reflect.ValueOf(str).Convert(typ)
+		}
+		if typ.Kind() == reflect.Int32 && typ.Name() != "int32" {
+			//an enum value by its name, the registry returns 0 for an unknown name so it is checked by the enum name
+			value := reflect.ValueOf(this.property.resources.Registry().Enum(str)).Convert(typ)
+			stringer, ok := value.Interface().(fmt.Stringer)
+			if (ok && stringer.String() != str) || (!ok && value.Int() == 0) {
+				this.fail("unknown " + typ.Name() + " name " + literal)
+			}
+			return value
+		}
+		literal = str
+	}
+	var value interface{}
+	switch typ.Kind() {
+	case reflect.Bool:
+		value, err = strconv.ParseBool(literal)
+	case reflect.String:
+		value = literal
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err = strconv.ParseInt(literal, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err = strconv.ParseUint(literal, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		value, err = strconv.ParseFloat(literal, 64)
+	default:
+		err = errors.New("unsupported type")
+	}
+	if err != nil {
+		this.fail("cannot compare " + attr.FieldName + " of type " + typ.String() + " with " + literal)
+		return target
+	}
+	return reflect.ValueOf(value).Convert(typ)
+}
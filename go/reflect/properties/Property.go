@@ -19,6 +19,9 @@ type Property struct {
 	id        string
 	isLeaf    bool
 	resources ifs.IResources
+	//index is the position of a primary keyed slice element, when it is known
+	index    int
+	hasIndex bool
 }
 
 func NewProperty(node *l8reflect.L8Node, parent *Property, key interface{}, value interface{}, resources ifs.IResources) *Property {
@@ -36,12 +39,34 @@ func NewProperty(node *l8reflect.L8Node, parent *Property, key interface{}, valu
 }
 
 func PropertyOf(propertyId string, resources ifs.IResources) (*Property, error) {
-	propertyKey := helping.PropertyNodeKey(propertyId)
-	node, ok := resources.Introspector().Node(propertyKey)
+	path, err := ParsePropertyPath(propertyId)
+	if err != nil {
+		return nil, err
+	}
+	node, ok := nodeOf(path, resources)
 	if !ok {
-		return nil, errors.New("Unknown attribute " + propertyKey)
+		return nil, errors.New("Unknown attribute " + path.NodeKey())
+	}
+	return newProperty(node, path, len(path.Segments)-1, resources)
+}
+
+// nodeOf returns the node of the path, whose names may be the field names or their json or protobuf names.
+func nodeOf(path *PropertyPath, resources ifs.IResources) (*l8reflect.L8Node, bool) {
+	node, ok := resources.Introspector().Node(path.NodeKey())
+	if ok {
+		return node, true
+	}
+	node, ok = resources.Introspector().Node(path.Segments[0].Name)
+	if !ok {
+		return nil, false
+	}
+	for _, segment := range path.Segments[1:] {
+		node = helping.AttributeByName(node, segment.Name)
+		if node == nil {
+			return nil, false
+		}
 	}
-	return newProperty(node, propertyId, resources)
+	return node, true
 }
 
 func (this *Property) Parent() ifs.IProperty {
@@ -56,6 +81,17 @@ func (this *Property) Key() interface{} {
 	return this.key
 }
 
+// SetIndex sets the position of the primary keyed slice element of the property, at the time its change is applied.
+func (this *Property) SetIndex(index int) {
+	this.index = index
+	this.hasIndex = true
+}
+
+// Index returns the position of the primary keyed slice element of the property, if it was set.
+func (this *Property) Index() (int, bool) {
+	return this.index, this.hasIndex
+}
+
 func (this *Property) Value() interface{} {
 	return this.value
 }
@@ -64,43 +100,6 @@ func (this *Property) Resources() ifs.IResources {
 	return this.resources
 }
 
-func (this *Property) setKeyValue(propertyId string) (string, error) {
-	id := propertyId
-	dIndex := strings.LastIndex(propertyId, ".")
-	if dIndex == -1 {
-		return "", nil
-	}
-	beIndex := strings.LastIndex(propertyId, ">")
-	if beIndex == -1 {
-		return "", nil
-	}
-
-	if dIndex > beIndex {
-		prefix := propertyId[0:dIndex]
-		return prefix, nil
-	}
-
-	bsIndex := strings.LastIndex(propertyId, "<")
-	if dIndex > bsIndex {
-		id = propertyId[:bsIndex]
-		dIndex = strings.LastIndex(id, ".")
-	}
-	prefix := propertyId[0:dIndex]
-	suffix := propertyId[dIndex+1:]
-	bbIndex := strings.LastIndex(suffix, "<")
-	if bbIndex == -1 {
-		return prefix, nil
-	}
-
-	v := suffix[bbIndex+1 : len(suffix)-1]
-	k, e := strings2.FromString(v, this.resources.Registry())
-	if e != nil {
-		return "", e
-	}
-	this.key = k.Interface()
-	return prefix, nil
-}
-
 func (this *Property) IsString() bool {
 	if this.node.TypeName == reflect.String.String() {
 		return true
@@ -126,46 +125,91 @@ func (this *Property) PropertyId() (string, error) {
 		buff.Add(strings.ToLower(this.node.FieldName))
 	}
 	if this.key != nil {
-		keyStr := strings2.New()
-		keyStr.TypesPrefix = true
 		buff.Add("<")
-		buff.Add(keyStr.StringOf(this.key))
+		buff.Add(keyString(this.key))
 		buff.Add(">")
 	}
 	this.id = buff.String()
 	return this.id, nil
 }
 
+// NameStyle is the naming of the fields in a property id.
+type NameStyle int
+
+const (
+	//GoNames are the lowercased go field names, as in PropertyId
+	GoNames NameStyle = iota
+	//JsonNames are the json names of the fields, e.g. adminStatus
+	JsonNames
+	//ProtoNames are the protobuf names of the fields, e.g. admin_status
+	ProtoNames
+)
+
+// PropertyIdAs returns the property id with the field names in the style,
+// a field without a name in the style is named by its lowercased go field name.
+func (this *Property) PropertyIdAs(style NameStyle) (string, error) {
+	if style == GoNames {
+		return this.PropertyId()
+	}
+	buff := strings2.New()
+	if this.parent == nil {
+		buff.Add(strings.ToLower(this.node.TypeName))
+	} else {
+		pi, err := this.parent.PropertyIdAs(style)
+		if err != nil {
+			return "", err
+		}
+		buff.Add(pi)
+		buff.Add(".")
+		buff.Add(styledName(this.node, style))
+	}
+	if this.key != nil {
+		buff.Add("<")
+		buff.Add(keyString(this.key))
+		buff.Add(">")
+	}
+	return buff.String(), nil
+}
+
+func styledName(node *l8reflect.L8Node, style NameStyle) string {
+	name := ""
+	switch style {
+	case JsonNames:
+		name = helping.DecoratorName(node, helping.JsonNameDecorator)
+	case ProtoNames:
+		name = helping.DecoratorName(node, helping.ProtoNameDecorator)
+	}
+	if name == "" {
+		return strings.ToLower(node.FieldName)
+	}
+	return name
+}
+
 func (this *Property) IsLeaf() bool {
 	return this.isLeaf
 }
 
-func newProperty(node *l8reflect.L8Node, propertyPath string, resources ifs.IResources) (*Property, error) {
+// newProperty creates the property of the node at the index segment of the path, with its parents.
+func newProperty(node *l8reflect.L8Node, path *PropertyPath, index int, resources ifs.IResources) (*Property, error) {
 	property := &Property{}
 	property.isLeaf = true
 	property.node = node
 	property.resources = resources
 	if node.Parent != nil {
-		prefix, err := property.setKeyValue(propertyPath)
-		if err != nil {
-			return nil, err
-		}
-		pi, err := newProperty(node.Parent, prefix, resources)
+		pi, err := newProperty(node.Parent, path, index-1, resources)
 		if err != nil {
 			return nil, err
 		}
 		property.parent = pi
 		pi.isLeaf = false
-	} else {
-		index1 := strings.Index(propertyPath, "<")
-		index2 := strings.Index(propertyPath, ">")
-		if index1 != -1 && index2 != -1 && index2 > index1 {
-			k, e := strings2.FromString(propertyPath[index1+1:index2], property.resources.Registry())
-			if e != nil {
-				return nil, e
-			}
-			property.key = k.Interface()
+	}
+	segment := path.Segments[index]
+	if segment.Key != nil {
+		k, err := parseKey(segment.Key, path.Id, property)
+		if err != nil {
+			return nil, err
 		}
+		property.key = k
 	}
 	return property, nil
 }
@@ -0,0 +1,193 @@
+package properties
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	strings2 "github.com/saichler/l8utils/go/utils/strings"
+)
+
+// PropertyPath is a parsed property id, its segments are the root type and then the field names.
+//
+//	id        = segment { "." segment }
+//	segment   = name [ "<" key ">" ]
+//	name      = letter or digit or "_" { letter or digit or "_" }
+//	key       = "*" | "?" predicate | keyatom { "|" keyatom }
+//	keyatom   = { any character but "\" "<" ">" "|" | "\" any character }
+//	predicate = ends with a ">", outside of a quoted string, that is followed by a "." or the end of the id
+type PropertyPath struct {
+	Id       string
+	Segments []*PathSegment
+}
+
+// PathSegment is a type or a field name of a property id, with its key if it has one.
+// Column is the 1 based column of the name in the id.
+type PathSegment struct {
+	Name   string
+	Column int
+	Key    *PathKey
+}
+
+// PathKey is the key of a segment. Keys are unescaped and more than one key is a key list.
+type PathKey struct {
+	Column    int
+	Wildcard  bool
+	Predicate string
+	Keys      []string
+}
+
+// PathError is a property id that can't be parsed, Column is the 1 based column of the offending character.
+type PathError struct {
+	Id     string
+	Column int
+	Reason string
+}
+
+func (this *PathError) Error() string {
+	return "Invalid property id " + this.Id + " at column " + strconv.Itoa(this.Column) + ": " + this.Reason
+}
+
+// ParsePropertyPath parses the property id to its segments.
+func ParsePropertyPath(id string) (*PropertyPath, error) {
+	parser := &pathParser{id: id}
+	path := &PropertyPath{Id: id}
+	for {
+		segment, err := parser.segment()
+		if err != nil {
+			return nil, err
+		}
+		path.Segments = append(path.Segments, segment)
+		if parser.pos == len(id) {
+			return path, nil
+		}
+		if id[parser.pos] != '.' {
+			return nil, parser.fail(parser.pos, "expected '.' after "+segment.Name)
+		}
+		parser.pos++
+	}
+}
+
+// NodeKey is the property id without the keys, the key of its node in the introspector.
+func (this *PropertyPath) NodeKey() string {
+	buff := strings2.New()
+	for i, segment := range this.Segments {
+		if i > 0 {
+			buff.Add(".")
+		}
+		buff.Add(segment.Name)
+	}
+	return buff.String()
+}
+
+// String is the property id of the path, with its keys escaped.
+func (this *PropertyPath) String() string {
+	buff := strings2.New()
+	for i, segment := range this.Segments {
+		if i > 0 {
+			buff.Add(".")
+		}
+		buff.Add(segment.Name)
+		if segment.Key != nil {
+			buff.Add("<")
+			buff.Add(segment.Key.String())
+			buff.Add(">")
+		}
+	}
+	return buff.String()
+}
+
+func (this *PathKey) String() string {
+	if this.Wildcard {
+		return "*"
+	}
+	if this.Predicate != "" {
+		return "?" + this.Predicate
+	}
+	buff := strings2.New()
+	for i, key := range this.Keys {
+		if i > 0 {
+			buff.Add("|")
+		}
+		buff.Add(helping.EscapeKey(key))
+	}
+	return buff.String()
+}
+
+type pathParser struct {
+	id  string
+	pos int
+}
+
+func (this *pathParser) fail(pos int, reason string) error {
+	return &PathError{Id: this.id, Column: pos + 1, Reason: reason}
+}
+
+func (this *pathParser) segment() (*PathSegment, error) {
+	start := this.pos
+	for this.pos < len(this.id) && isNameChar(this.id[this.pos]) {
+		this.pos++
+	}
+	if this.pos == start {
+		if this.pos == len(this.id) {
+			return nil, this.fail(this.pos, "expected a name at the end")
+		}
+		return nil, this.fail(this.pos, "unexpected '"+this.id[this.pos:this.pos+1]+"'")
+	}
+	segment := &PathSegment{Name: this.id[start:this.pos], Column: start + 1}
+	if this.pos < len(this.id) && this.id[this.pos] == '<' {
+		key, err := this.key()
+		if err != nil {
+			return nil, err
+		}
+		segment.Key = key
+	}
+	return segment, nil
+}
+
+func (this *pathParser) key() (*PathKey, error) {
+	start := this.pos
+	end := helping.KeyEnd(this.id, start)
+	if end == -1 {
+		return nil, this.fail(start, "missing '>' for the '<'")
+	}
+	key := &PathKey{Column: start + 2}
+	body := this.id[start+1 : end]
+	this.pos = end + 1
+	switch {
+	case body == "*":
+		key.Wildcard = true
+		return key, nil
+	case strings.HasPrefix(body, "?"):
+		if len(body) == 1 {
+			return nil, this.fail(start+1, "empty predicate")
+		}
+		key.Predicate = body[1:]
+		return key, nil
+	}
+	buff := strings2.New()
+	atomStart := start + 1
+	for i := start + 1; i <= end; i++ {
+		switch this.id[i] {
+		case '\\':
+			i++
+			buff.Add(this.id[i : i+1])
+		case '<':
+			return nil, this.fail(i, "unescaped '<' in a key")
+		case '|', '>':
+			if i == atomStart {
+				return nil, this.fail(i, "empty key")
+			}
+			key.Keys = append(key.Keys, buff.String())
+			buff = strings2.New()
+			atomStart = i + 1
+		default:
+			buff.Add(this.id[i : i+1])
+		}
+	}
+	return key, nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -13,6 +13,10 @@ func (this *Property) Set(any interface{}, value interface{}) (interface{}, inte
 	if this == nil {
 		return nil, nil, errors.New("property is nil, cannot instantiate")
 	}
+	if isPatternKey(this.key) {
+		p, _ := this.PropertyId()
+		return nil, nil, errors.New("Cannot set a property id with a wildcard or a key list: " + p)
+	}
 	if this.parent == nil {
 		if any == nil {
 			info, err := this.resources.Registry().Info(this.node.TypeName)
@@ -53,6 +57,28 @@ func (this *Property) Set(any interface{}, value interface{}) (interface{}, inte
 	}
 
 	myValue := parentValue.FieldByName(this.node.FieldName)
+	//A field behind an interface is set on the struct its pointer points to
+	if myValue.Kind() == reflect.Interface && !this.IsLeaf() {
+		if myValue.IsNil() || myValue.Elem().Kind() != reflect.Ptr || myValue.Elem().IsNil() {
+			p, _ := this.PropertyId()
+			return nil, any, errors.New("Cannot set a field behind the interface of PID: " + p + ", it is not a struct pointer")
+		}
+		return myValue.Elem().Interface(), any, nil
+	}
+	//Arrays & interfaces are set as a whole, their type name is not in the registry
+	if myValue.Kind() == reflect.Array || myValue.Kind() == reflect.Interface {
+		if value == nil {
+			myValue.Set(reflect.Zero(myValue.Type()))
+			return nil, any, nil
+		}
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(myValue.Type()) {
+			p, _ := this.PropertyId()
+			return nil, any, errors.New("Invalid value type " + v.Type().String() + " for PID: " + p)
+		}
+		myValue.Set(v)
+		return value, any, nil
+	}
 	info, err := this.resources.Registry().Info(this.node.TypeName)
 	if err != nil {
 		return nil, nil, err
@@ -0,0 +1,72 @@
+package properties
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// SliceInsert is set as the value of a slice element, to insert Value at the index, shifting the following elements.
+type SliceInsert struct {
+	Value interface{}
+}
+
+// SliceRemove is set as the value of a slice element, to remove the element at the index, shifting the following elements.
+type SliceRemove struct{}
+
+var sliceInsertType = reflect.TypeOf(SliceInsert{})
+var sliceRemoveType = reflect.TypeOf(SliceRemove{})
+
+func isSliceEdit(value reflect.Value) bool {
+	return value.IsValid() && (value.Type() == sliceInsertType || value.Type() == sliceRemoveType ||
+		value.Type() == entryDeleteType || value.Type() == moveToType)
+}
+
+// sliceEditSet inserts, removes or moves the element at index, as opposed to replacing it.
+func (this *Property) sliceEditSet(myValue reflect.Value, newValue reflect.Value, index int) (interface{}, error) {
+	if !myValue.IsValid() || myValue.IsNil() {
+		myValue.Set(reflect.MakeSlice(myValue.Type(), 0, 0))
+	}
+	pid, _ := this.PropertyId()
+
+	if newValue.Type() == sliceRemoveType || newValue.Type() == entryDeleteType {
+		if index < 0 || index >= myValue.Len() {
+			return nil, errors.New("Cannot remove index " + strconv.Itoa(index) + " from " + pid)
+		}
+		newSlice := reflect.MakeSlice(myValue.Type(), 0, myValue.Len()-1)
+		newSlice = reflect.AppendSlice(newSlice, myValue.Slice(0, index))
+		newSlice = reflect.AppendSlice(newSlice, myValue.Slice(index+1, myValue.Len()))
+		myValue.Set(newSlice)
+		return myValue.Interface(), nil
+	}
+
+	if newValue.Type() == moveToType {
+		to := int(newValue.Int())
+		if index < 0 || index >= myValue.Len() || to < 0 || to >= myValue.Len() {
+			return nil, errors.New("Cannot move index " + strconv.Itoa(index) + " of " + pid + " to index " + strconv.Itoa(to))
+		}
+		myValue.Set(moveElement(myValue, index, to))
+		return myValue.Interface(), nil
+	}
+
+	if index < 0 || index > myValue.Len() {
+		return nil, errors.New("Cannot insert at index " + strconv.Itoa(index) + " of " + pid)
+	}
+	elem := reflect.Zero(myValue.Type().Elem())
+	insert := newValue.Interface().(SliceInsert)
+	if insert.Value != nil {
+		elem = reflect.ValueOf(insert.Value)
+		if !elem.Type().AssignableTo(myValue.Type().Elem()) {
+			elem = ConvertValue(reflect.New(myValue.Type().Elem()).Elem(), elem)
+		}
+		if !elem.Type().AssignableTo(myValue.Type().Elem()) {
+			return nil, errors.New("Invalid element type " + elem.Type().String() + " for PID: " + pid)
+		}
+	}
+	newSlice := reflect.MakeSlice(myValue.Type(), 0, myValue.Len()+1)
+	newSlice = reflect.AppendSlice(newSlice, myValue.Slice(0, index))
+	newSlice = reflect.Append(newSlice, elem)
+	newSlice = reflect.AppendSlice(newSlice, myValue.Slice(index, myValue.Len()))
+	myValue.Set(newSlice)
+	return myValue.Interface(), nil
+}
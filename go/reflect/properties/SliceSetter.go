@@ -3,8 +3,6 @@ package properties
 import (
 	"errors"
 	"reflect"
-
-	"github.com/saichler/l8types/go/ifs"
 )
 
 func (this *Property) sliceSet(myValue reflect.Value, newSliceValue reflect.Value) (interface{}, error) {
@@ -39,7 +37,13 @@ func (this *Property) sliceSet(myValue reflect.Value, newSliceValue reflect.Valu
 		return nil, nil // Return nil for setting nil on slice without index
 	}
 
-	index := this.key.(int)
+	index, ok := this.key.(int)
+	if !ok {
+		return this.keyedSliceSet(myValue, newSliceValue)
+	}
+	if this.IsLeaf() && isSliceEdit(newSliceValue) {
+		return this.sliceEditSet(myValue, newSliceValue, index)
+	}
 	info, err := this.resources.Registry().Info(this.node.TypeName)
 	if err != nil {
 		return nil, err
@@ -54,16 +58,15 @@ func (this *Property) sliceSet(myValue reflect.Value, newSliceValue reflect.Valu
 		}
 	}
 
-	//If elements were delete from the slice,
-	//reduce the size of the slice
-	if newSliceValue.Kind() == reflect.String && newSliceValue.String() == ifs.Deleted_Entry {
+	//The ifs.Deleted_Entry string at an index truncates the slice, as a truncation was set before SliceRemove
+	if isDeletedEntry(newSliceValue) {
 		var newSlice reflect.Value
 		if this.node.IsStruct {
 			newSlice = reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(info.Type())), index, index)
 		} else {
 			newSlice = reflect.MakeSlice(reflect.SliceOf(info.Type()), index, index)
 		}
-		for i := 0; i < index; i++ {
+		for i := 0; i < index && i < myValue.Len(); i++ {
 			newSlice.Index(i).Set(myValue.Index(i))
 		}
 		myValue.Set(newSlice)
@@ -94,13 +97,23 @@ func (this *Property) sliceSet(myValue reflect.Value, newSliceValue reflect.Valu
 		return oIndexValue.Interface(), nil
 	}
 
-	if newSliceValue.Kind() != reflect.Slice {
-		pid, _ := this.PropertyId()
-		return nil, errors.New("No a slice new value PID: " + pid)
+	//The new value is either the whole new slice or just the element at the index
+	var nIndexValue reflect.Value
+	if !newSliceValue.IsValid() {
+		nIndexValue = reflect.Zero(oIndexValue.Type())
+	} else if newSliceValue.Kind() == reflect.Slice && !newSliceValue.Type().AssignableTo(oIndexValue.Type()) {
+		nIndexValue = newSliceValue.Index(index)
+	} else {
+		nIndexValue = newSliceValue
+		if !nIndexValue.Type().AssignableTo(oIndexValue.Type()) {
+			nIndexValue = ConvertValue(oIndexValue, nIndexValue)
+		}
+		if !nIndexValue.Type().AssignableTo(oIndexValue.Type()) {
+			pid, _ := this.PropertyId()
+			return nil, errors.New("Invalid element type " + newSliceValue.Type().String() + " for PID: " + pid)
+		}
 	}
 
-	nIndexValue := newSliceValue.Index(index)
-
 	//If this is not a leaf property
 	//We need to continue drilling down
 	if this.node.IsStruct && !this.IsLeaf() {
@@ -0,0 +1,235 @@
+package properties
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	strings2 "github.com/saichler/l8utils/go/utils/strings"
+)
+
+// Wildcard is the key of a property id segment matching every map key or slice element, e.g. "model.items<*>.name".
+type Wildcard struct{}
+
+// KeyList is the key of a property id segment matching a list of keys, e.g. "model.items<{24}a|{24}b>.name".
+type KeyList struct {
+	Keys []interface{}
+}
+
+// Match is a value matched by a property id with wildcards or key lists, with its fully keyed property id.
+type Match struct {
+	PropertyId string
+	Value      interface{}
+}
+
+type match struct {
+	id    string
+	value reflect.Value
+}
+
+// parseKey parses the key of a property id segment, a "*" wildcard, a predicate, a "|" separated key list or a single key.
+func parseKey(key *PathKey, id string, property *Property) (interface{}, error) {
+	if key.Wildcard {
+		return Wildcard{}, nil
+	}
+	if key.Predicate != "" {
+		return parsePredicate(key.Predicate, property.node, property, id, key.Column)
+	}
+	keys := &KeyList{}
+	for _, s := range key.Keys {
+		k, err := strings2.FromString(s, property.resources.Registry())
+		if err != nil {
+			return nil, &PathError{Id: id, Column: key.Column, Reason: err.Error()}
+		}
+		keys.Keys = append(keys.Keys, k.Interface())
+	}
+	if len(keys.Keys) == 1 {
+		return keys.Keys[0], nil
+	}
+	return *keys, nil
+}
+
+func isPatternKey(key interface{}) bool {
+	switch key.(type) {
+	case Wildcard, KeyList, Predicate:
+		return true
+	}
+	return false
+}
+
+// IsPattern returns true if the property id, or any of its parents, has a wildcard or a key list.
+func (this *Property) IsPattern() bool {
+	for property := this; property != nil; property = property.parent {
+		if isPatternKey(property.key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns every value of the property in any, with its fully keyed property id.
+// Map entries are returned sorted by their keys and slice elements by their index.
+func (this *Property) Matches(any interface{}) ([]*Match, error) {
+	if any == nil {
+		return nil, errors.New("cannot match " + propertyIdOf(this) + " in a nil instance")
+	}
+	matches := this.matches(reflect.ValueOf(any))
+	result := make([]*Match, 0, len(matches))
+	for _, m := range matches {
+		if !m.value.IsValid() {
+			continue
+		}
+		result = append(result, &Match{PropertyId: m.id, Value: m.value.Interface()})
+	}
+	return result, nil
+}
+
+func (this *Property) matches(root reflect.Value) []match {
+	if this.parent == nil {
+		if !root.IsValid() || (root.Kind() == reflect.Ptr && root.IsNil()) {
+			return nil
+		}
+		id := strings.ToLower(this.node.TypeName)
+		if this.key != nil && !isPatternKey(this.key) {
+			id = helping.KeyId(id, this.key)
+		}
+		return []match{{id: id, value: root}}
+	}
+	result := make([]match, 0)
+	for _, parent := range this.parent.matches(root) {
+		//a parent map or slice without a key matches all its elements
+		elements := []match{parent}
+		if elem(parent.value).Kind() == reflect.Map || elem(parent.value).Kind() == reflect.Slice {
+			elements = this.parent.elements(parent, Wildcard{})
+		}
+		for _, element := range elements {
+			value := elem(element.value)
+			if value.Kind() != reflect.Struct {
+				continue
+			}
+			field := match{id: element.id + "." + strings.ToLower(this.node.FieldName), value: value.FieldByName(this.node.FieldName)}
+			if this.key != nil && (field.value.Kind() == reflect.Map || field.value.Kind() == reflect.Slice) {
+				result = append(result, this.elements(field, this.key)...)
+			} else {
+				result = append(result, field)
+			}
+		}
+	}
+	return result
+}
+
+// elements returns the elements of the map or slice at the key, which may be a wildcard or a key list.
+func (this *Property) elements(container match, key interface{}) []match {
+	value := elem(container.value)
+	result := make([]match, 0)
+	if !value.IsValid() {
+		return result
+	}
+	var keys []interface{}
+	switch k := key.(type) {
+	case Wildcard, Predicate:
+		if value.Kind() == reflect.Map {
+			mapKeys := value.MapKeys()
+			sort.Slice(mapKeys, func(i, j int) bool {
+				return lessKey(mapKeys[i], mapKeys[j])
+			})
+			keys = make([]interface{}, len(mapKeys))
+			for i, mapKey := range mapKeys {
+				keys[i] = mapKey.Interface()
+			}
+		} else {
+			//the elements of a primary keyed slice are identified by their primary key
+			primary := helping.PrimaryDecoratorFields(this.node, this.resources.Registry()) != nil
+			keys = make([]interface{}, 0, value.Len())
+			for i := 0; i < value.Len(); i++ {
+				if !primary {
+					keys = append(keys, i)
+				} else if element := elem(value.Index(i)); element.IsValid() {
+					keys = append(keys, helping.PrimaryDecorator(this.node, element, this.resources.Registry()))
+				}
+			}
+		}
+	case KeyList:
+		keys = k.Keys
+	default:
+		keys = []interface{}{key}
+	}
+	for _, k := range keys {
+		var element reflect.Value
+		if value.Kind() == reflect.Map {
+			mapKey := reflect.ValueOf(k)
+			if !mapKey.IsValid() || !mapKey.Type().AssignableTo(value.Type().Key()) {
+				continue
+			}
+			element = value.MapIndex(mapKey)
+		} else {
+			index, ok := k.(int)
+			if !ok {
+				index = helping.PrimaryKeyIndex(this.node, value, k, this.resources.Registry())
+			}
+			if index < 0 || index >= value.Len() {
+				continue
+			}
+			element = value.Index(index)
+		}
+		if !element.IsValid() {
+			continue
+		}
+		if predicate, ok := key.(Predicate); ok && !predicate.expr.eval(element) {
+			continue
+		}
+		result = append(result, match{id: helping.KeyId(container.id, k), value: element})
+	}
+	return result
+}
+
+// lessKey orders map keys by their native order, numbers by value and strings lexically.
+// Keys of other kinds are ordered by their string.
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	str := strings2.New()
+	return str.StringOf(a.Interface()) < str.StringOf(b.Interface())
+}
+
+func elem(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// keyString is the escaped key as it is in a property id, including wildcards and key lists.
+func keyString(key interface{}) string {
+	switch k := key.(type) {
+	case Wildcard:
+		return "*"
+	case Predicate:
+		return "?" + k.source
+	case KeyList:
+		buff := strings2.New()
+		for i, listKey := range k.Keys {
+			if i > 0 {
+				buff.Add("|")
+			}
+			buff.Add(helping.KeyString(listKey))
+		}
+		return buff.String()
+	}
+	return helping.KeyString(key)
+}
@@ -0,0 +1,200 @@
+package updating
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8utils/go/utils/strings"
+)
+
+// BatchError is returned by ApplyBatch, naming the change that failed and the rollback error, if any.
+type BatchError struct {
+	PropertyId  string
+	Index       int
+	Err         error
+	RollbackErr error
+}
+
+func (this *BatchError) Error() string {
+	str := strings.New("Failed to apply change #")
+	str.Add(str.StringOf(this.Index)).Add(" on ").Add(this.PropertyId).Add(": ").Add(this.Err.Error())
+	if this.RollbackErr != nil {
+		str.Add(", rollback failed: ").Add(this.RollbackErr.Error())
+	}
+	return str.String()
+}
+
+func (this *BatchError) Unwrap() error {
+	return this.Err
+}
+
+// ApplyBatch applies all the changes on any, or none of them. On the first failure, the changes that were applied
+// are undone in reverse order, restoring the values they replaced in place, and a *BatchError is returned.
+// When checked is true, every change is applied with ApplyChecked.
+func ApplyBatch(any interface{}, changes []*Change, checked bool) error {
+	target := reflect.ValueOf(any)
+	if !target.IsValid() || target.Kind() != reflect.Ptr || target.IsNil() {
+		return errors.New("batch can only be applied on a non nil pointer")
+	}
+	undos := make([][]*Change, 0, len(changes))
+	for i, change := range changes {
+		undo, creates := undoOf(any, change)
+		err := applySafe(func() error {
+			if checked {
+				return change.ApplyChecked(any)
+			}
+			return change.Apply(any)
+		})
+		if err != nil {
+			//the failed change may have created the missing parents of its property before failing
+			if creates {
+				undos = append(undos, undo)
+			}
+			batchErr := &BatchError{PropertyId: change.PropertyId(), Index: i, Err: err}
+			batchErr.RollbackErr = rollback(any, undos)
+			return batchErr
+		}
+		undos = append(undos, undo)
+	}
+	return nil
+}
+
+// rollback applies the undo changes of the applied changes, the last applied change first.
+func rollback(any interface{}, undos [][]*Change) error {
+	for i := len(undos) - 1; i >= 0; i-- {
+		for _, change := range undos[i] {
+			err := applySafe(func() error {
+				return change.Apply(any)
+			})
+			if err != nil {
+				return errors.New("Failed to undo " + change.PropertyId() + ": " + err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// undoOf returns the changes that restore the values of any the change is about to replace.
+// A missing map entry, slice element or nil pointer the change creates on its way is removed again,
+// in which case true is returned.
+func undoOf(any interface{}, change *Change) ([]*Change, bool) {
+	ancestors := make([]*properties.Property, 0)
+	for p, _ := change.property.Parent().(*properties.Property); p != nil; p, _ = p.Parent().(*properties.Property) {
+		if p.Parent() != nil {
+			ancestors = append([]*properties.Property{p}, ancestors...)
+		}
+	}
+	for _, ancestor := range ancestors {
+		current, exist := NewChange(nil, nil, ancestor).currentValue(any)
+		if exist && !isNilValue(current) {
+			continue
+		}
+		//a fresh property sets the ancestor itself instead of drilling down through it
+		parent := ancestor.Parent().(*properties.Property)
+		leaf := properties.NewProperty(ancestor.Node(), parent, ancestor.Key(), nil, ancestor.Resources())
+		if !exist && ancestor.Key() != nil {
+			return []*Change{NewChange(nil, properties.EntryDelete{}, leaf)}, true
+		}
+		return []*Change{NewChange(nil, current, leaf)}, true
+	}
+	return valueUndo(any, change), false
+}
+
+func valueUndo(any interface{}, change *Change) []*Change {
+
+	property := change.property
+	current, exist := change.currentValue(any)
+	_, intKey := property.Key().(int)
+	newValue := change.newValue
+	if isEntryDelete(newValue) && newValue != (properties.EntryDelete{}) {
+		if intKey && property.Node().IsSlice {
+			return truncationUndo(any, property)
+		}
+		newValue = properties.EntryDelete{}
+	}
+	switch value := newValue.(type) {
+	case properties.SliceInsert:
+		return []*Change{NewChange(nil, properties.SliceRemove{}, property)}
+	case properties.SliceRemove:
+		if !exist {
+			return nil
+		}
+		return []*Change{NewChange(nil, properties.SliceInsert{Value: current}, property)}
+	case properties.MoveTo:
+		if !exist {
+			return nil
+		}
+		if intKey {
+			parent := property.Parent().(*properties.Property)
+			moved := properties.NewProperty(property.Node(), parent, int(value), nil, property.Resources())
+			return []*Change{NewChange(nil, properties.MoveTo(property.Key().(int)), moved)}
+		}
+		return []*Change{NewChange(nil, current, property)}
+	case properties.EntryDelete:
+		if !exist {
+			return nil
+		}
+		if !property.Node().IsSlice {
+			return []*Change{NewChange(nil, current, property)}
+		}
+		if intKey {
+			return []*Change{NewChange(nil, properties.SliceInsert{Value: current}, property)}
+		}
+		//a primary keyed element is re-appended and moved back to its position
+		index, _ := NewChange(nil, properties.MoveTo(0), property).currentValue(any)
+		return []*Change{NewChange(nil, current, property), NewChange(nil, index, property)}
+	}
+	if !exist && property.Key() != nil {
+		return []*Change{NewChange(nil, properties.EntryDelete{}, property)}
+	}
+	return []*Change{NewChange(nil, current, property)}
+}
+
+// truncationUndo inserts back the elements the ifs.Deleted_Entry string at the index of the slice truncates,
+// the last one first, as each is inserted at the index.
+func truncationUndo(any interface{}, property *properties.Property) []*Change {
+	parent := property.Parent().(*properties.Property)
+	undo := make([]*Change, 0)
+	for i := property.Key().(int); ; i++ {
+		element := properties.NewProperty(property.Node(), parent, i, nil, property.Resources())
+		current, exist := NewChange(nil, nil, element).currentValue(any)
+		if !exist {
+			return undo
+		}
+		undo = append([]*Change{NewChange(nil, properties.SliceInsert{Value: current}, property)}, undo...)
+	}
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// applySafe returns the panics of the reflect package as errors, so the batch can be rolled back,
+// any other panic is not recovered.
+func applySafe(apply func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !isReflectPanic(r) {
+				panic(r)
+			}
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	return apply()
+}
+
+func isReflectPanic(r interface{}) bool {
+	switch v := r.(type) {
+	case *reflect.ValueError:
+		return true
+	case string:
+		return len(v) > 7 && v[0:7] == "reflect"
+	}
+	return false
+}
@@ -1,14 +1,20 @@
 package updating
 
 import (
+	"reflect"
+
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
 	"github.com/saichler/l8reflect/go/reflect/properties"
-	"github.com/saichler/l8utils/go/utils/strings"
+	strings2 "github.com/saichler/l8utils/go/utils/strings"
 )
 
 type Change struct {
 	property *properties.Property
 	oldValue interface{}
 	newValue interface{}
+	//index is the position of a deleted primary keyed slice element
+	index int
 }
 
 func (this *Change) String() (string, error) {
@@ -16,15 +22,126 @@ func (this *Change) String() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	str := strings.New(id)
+	str := strings2.New(id)
+	oldValue, newValue := this.redacted(id)
 
-	str.Add(" - Old=").Add(str.StringOf(this.oldValue)).
-		Add(" New=").Add(str.StringOf(this.newValue))
+	str.Add(" - Old=").Add(str.StringOf(oldValue)).
+		Add(" New=").Add(str.StringOf(newValue))
 	return str.String(), nil
 }
 
-func (this *Change) Apply(any interface{}) {
-	this.property.Set(any, this.newValue)
+// redacted returns the old and new values with the sensitive nodes values masked, by the redactor the introspector
+// built when the nodes were made sensitive.
+func (this *Change) redacted(id string) (interface{}, interface{}) {
+	node := this.property.Node()
+	resources := this.property.Resources()
+	if resources == nil {
+		return this.oldValue, this.newValue
+	}
+	introspector, ok := resources.Introspector().(*introspecting.Introspector)
+	if !ok {
+		return this.oldValue, this.newValue
+	}
+	redactor := introspector.Redactor(node)
+	if redactor == nil {
+		return this.oldValue, this.newValue
+	}
+	nodePath := helping.NodeCacheKey(node)
+	redact := func(value interface{}) interface{} {
+		if isEntryDelete(value) {
+			return value
+		}
+		switch v := value.(type) {
+		case properties.SliceInsert:
+			return properties.SliceInsert{Value: redactor.CloneAt(v.Value, id, nodePath)}
+		case properties.SliceRemove, properties.MoveTo, properties.EntryDelete:
+			return value
+		}
+		return redactor.CloneAt(value, id, nodePath)
+	}
+	return redact(this.oldValue), redact(this.newValue)
+}
+
+// ConflictError is returned by ApplyChecked when the current value is not the change old value.
+type ConflictError struct {
+	PropertyId string
+	Expected   interface{}
+	Actual     interface{}
+}
+
+func (this *ConflictError) Error() string {
+	str := strings2.New("Conflict on ")
+	str.Add(this.PropertyId).Add(": expected ").Add(str.StringOf(this.Expected)).
+		Add(" but found ").Add(str.StringOf(this.Actual))
+	return str.String()
+}
+
+func (this *Change) Apply(any interface{}) error {
+	_, _, err := this.property.Set(any, this.newValue)
+	return err
+}
+
+// ApplyChecked applies the change only if the current value at the property id equals the change old value,
+// otherwise it returns a *ConflictError without modifying any.
+func (this *Change) ApplyChecked(any interface{}) error {
+	actual, exist := this.currentValue(any)
+	if !this.expected(actual, exist) {
+		return &ConflictError{PropertyId: this.PropertyId(), Expected: this.oldValue, Actual: actual}
+	}
+	return this.Apply(any)
+}
+
+func (this *Change) currentValue(any interface{}) (interface{}, bool) {
+	values := this.property.GetValue(reflect.ValueOf(any))
+	if len(values) != 1 || !values[0].IsValid() {
+		return nil, false
+	}
+	value := values[0]
+	key := this.property.Key()
+	parent, _ := this.property.Parent().(*properties.Property)
+	if key == nil || parent == nil {
+		return value.Interface(), true
+	}
+	if value.Kind() == reflect.Map {
+		entry := value.MapIndex(reflect.ValueOf(key))
+		if !entry.IsValid() {
+			return nil, false
+		}
+		return entry.Interface(), true
+	}
+	if value.Kind() == reflect.Slice {
+		index, ok := key.(int)
+		if !ok {
+			index = helping.PrimaryKeyIndex(this.property.Node(), value, key, this.property.Resources().Registry())
+			if index == -1 {
+				return nil, false
+			}
+			if _, ok := this.newValue.(properties.MoveTo); ok {
+				return properties.MoveTo(index), true
+			}
+			return value.Index(index).Interface(), true
+		}
+		//an insert only requires the index to be within the slice or right after its end
+		if _, ok := this.newValue.(properties.SliceInsert); ok {
+			return nil, index > value.Len()
+		}
+		if index >= value.Len() {
+			return nil, false
+		}
+		return value.Index(index).Interface(), true
+	}
+	return value.Interface(), true
+}
+
+func (this *Change) expected(actual interface{}, exist bool) bool {
+	if this.oldValue == nil {
+		//a new map entry or slice element must not exist yet
+		if this.property.Key() != nil && (this.property.Node().IsMap || this.property.Node().IsSlice) {
+			return !exist
+		}
+		return !exist || actual == nil || reflect.ValueOf(actual).IsZero()
+	}
+	return exist && deepEqual.Equal(actual, this.oldValue)
 }
 
 func (this *Change) PropertyId() string {
@@ -40,6 +157,37 @@ func (this *Change) NewValue() interface{} {
 	return this.newValue
 }
 
+// Operation is what a change does to its property, as the Property Set, Delete, Insert and Append.
+type Operation int
+
+const (
+	OperationSet Operation = iota + 1
+	OperationDelete
+	OperationInsert
+	OperationAppend
+	OperationMove
+)
+
+// Operation returns the operation of the change. A new slice element, by its index or by its primary key,
+// is appended to the slice.
+func (this *Change) Operation() Operation {
+	switch this.newValue.(type) {
+	case properties.SliceInsert:
+		return OperationInsert
+	case properties.SliceRemove:
+		return OperationDelete
+	case properties.MoveTo:
+		return OperationMove
+	}
+	if isEntryDelete(this.newValue) {
+		return OperationDelete
+	}
+	if this.oldValue == nil && this.property.Key() != nil && this.property.Node().IsSlice {
+		return OperationAppend
+	}
+	return OperationSet
+}
+
 func NewChange(old, new interface{}, property *properties.Property) *Change {
 	change := &Change{}
 	change.oldValue = old
@@ -1,6 +1,7 @@
 package updating
 
 import (
+	"bytes"
 	"reflect"
 
 	"github.com/saichler/l8types/go/types/l8reflect"
@@ -14,12 +15,17 @@ var deepEqual = cloning.NewDeepEqual()
 func init() {
 	comparators = make(map[reflect.Kind]func(*properties.Property, *l8reflect.L8Node, reflect.Value, reflect.Value, *Updater) error)
 	comparators[reflect.Int] = intUpdate
+	comparators[reflect.Int8] = intUpdate
+	comparators[reflect.Int16] = intUpdate
 	comparators[reflect.Int32] = intUpdate
 	comparators[reflect.Int64] = intUpdate
 
 	comparators[reflect.Uint] = uintUpdate
+	comparators[reflect.Uint8] = uintUpdate
+	comparators[reflect.Uint16] = uintUpdate
 	comparators[reflect.Uint32] = uintUpdate
 	comparators[reflect.Uint64] = uintUpdate
+	comparators[reflect.Uintptr] = uintUpdate
 
 	comparators[reflect.String] = stringUpdate
 
@@ -28,6 +34,9 @@ func init() {
 	comparators[reflect.Float32] = floatUpdate
 	comparators[reflect.Float64] = floatUpdate
 
+	comparators[reflect.Complex64] = complexUpdate
+	comparators[reflect.Complex128] = complexUpdate
+
 	comparators[reflect.Ptr] = ptrUpdate
 
 	comparators[reflect.Struct] = structUpdate
@@ -35,12 +44,16 @@ func init() {
 	comparators[reflect.Slice] = sliceUpdate
 
 	comparators[reflect.Map] = mapUpdate
+
+	comparators[reflect.Array] = arrayUpdate
+
+	comparators[reflect.Interface] = interfaceUpdate
 }
 
 func intUpdate(property *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
 	if oldValue.Int() != newValue.Int() && (newValue.Int() != 0 || updates.nilIsValid) {
 		updates.addUpdate(property, oldValue.Interface(), newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 	}
 	return nil
 }
@@ -48,7 +61,7 @@ func intUpdate(property *properties.Property, node *l8reflect.L8Node, oldValue,
 func uintUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
 	if oldValue.Uint() != newValue.Uint() && (newValue.Uint() != 0 || updates.nilIsValid) {
 		updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 	}
 	return nil
 }
@@ -56,7 +69,7 @@ func uintUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue,
 func stringUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
 	if oldValue.String() != newValue.String() && (newValue.String() != "" || updates.nilIsValid) {
 		updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 	}
 	return nil
 }
@@ -67,15 +80,96 @@ func boolUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue,
 	}
 	if newValue.Bool() && !oldValue.Bool() || updates.nilIsValid {
 		updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 	}
 	return nil
 }
 
 func floatUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
-	if oldValue.Float() != newValue.Float() && (newValue.Float() != 0 || updates.nilIsValid) {
+	if !updates.options().FloatEqual(oldValue.Float(), newValue.Float()) && (newValue.Float() != 0 || updates.nilIsValid) {
+		updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
+		updates.set(oldValue, newValue)
+	}
+	return nil
+}
+
+func complexUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	if oldValue.Complex() != newValue.Complex() && (newValue.Complex() != 0 || updates.nilIsValid) {
+		updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
+		updates.set(oldValue, newValue)
+	}
+	return nil
+}
+
+// arrayUpdate treats an array as a single leaf value, as arrays have a fixed size and no nodes for their elements.
+func arrayUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	if updates.isEqual(instance, oldValue, newValue) || (newValue.IsZero() && !updates.nilIsValid) {
+		return nil
+	}
+	updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
+	updates.set(oldValue, newValue)
+	return nil
+}
+
+// interfaceUpdate diffs an interface by its dynamic type, a value of a different type replaces the old one as a whole.
+// A pointer to a struct of an inspected type is diffed field by field and modified in place,
+// a scalar by the comparator of its kind, any other value is replaced as a whole.
+func interfaceUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	if oldValue.IsNil() && newValue.IsNil() {
+		return nil
+	}
+	if !oldValue.IsNil() && newValue.IsNil() {
+		if updates.nilIsValid {
+			updates.addUpdate(instance, oldValue.Interface(), nil)
+			updates.set(oldValue, newValue)
+		}
+		return nil
+	}
+	if oldValue.IsNil() || oldValue.Elem().Type() != newValue.Elem().Type() {
 		updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
+		return nil
+	}
+	oldElem := oldValue.Elem()
+	newElem := newValue.Elem()
+	switch oldElem.Kind() {
+	case reflect.Ptr:
+		if oldElem.Elem().Kind() == reflect.Struct && !oldElem.IsNil() && !newElem.IsNil() {
+			elemNode, ok := updates.resources.Introspector().Node(oldElem.Elem().Type().Name())
+			if ok {
+				return structUpdate(instance, elemNode, oldElem.Elem(), newElem.Elem(), updates)
+			}
+		}
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		//the value in an interface is not settable, so it is compared on a copy
+		elem := reflect.New(oldElem.Type()).Elem()
+		elem.Set(oldElem)
+		changes := len(updates.changes)
+		err := comparators[oldElem.Kind()](instance, node, elem, newElem, updates)
+		if err != nil {
+			return err
+		}
+		if len(updates.changes) > changes {
+			updates.set(oldValue, elem)
+		}
+		return nil
+	}
+	if updates.isEqual(instance, oldValue, newValue) {
+		return nil
+	}
+	updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
+	updates.set(oldValue, newValue)
+	return nil
+}
+
+// bytesUpdate treats a []byte as a single leaf value rather than a slice of uint8 elements.
+func bytesUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	if bytes.Equal(oldValue.Bytes(), newValue.Bytes()) || (newValue.Len() == 0 && !updates.nilIsValid) {
+		return nil
 	}
+	updates.addUpdate(instance, oldValue.Interface(), newValue.Interface())
+	updates.set(oldValue, newValue)
 	return nil
 }
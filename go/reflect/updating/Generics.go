@@ -0,0 +1,21 @@
+package updating
+
+import (
+	"github.com/saichler/l8reflect/go/reflect/helping"
+)
+
+// UpdateAs is Update for two instances of the same type.
+func UpdateAs[T any](updater *Updater, old, new T) error {
+	return updater.Update(old, new)
+}
+
+// ValuesAs returns the old and new values of the change as T.
+func ValuesAs[T any](change *Change) (T, T, error) {
+	oldValue, err := helping.As[T](change.oldValue)
+	if err != nil {
+		var zero T
+		return zero, zero, err
+	}
+	newValue, err := helping.As[T](change.newValue)
+	return oldValue, newValue, err
+}
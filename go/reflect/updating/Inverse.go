@@ -0,0 +1,66 @@
+package updating
+
+import (
+	"errors"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+// Inverse returns the changes that undo this change.
+func (this *Change) Inverse() []*Change {
+	//An element inserted at an index is undone by removing it and vice versa.
+	switch this.newValue.(type) {
+	case properties.SliceInsert:
+		return []*Change{NewChange(this.newValue, properties.SliceRemove{}, this.property)}
+	case properties.SliceRemove:
+		return []*Change{NewChange(nil, properties.SliceInsert{Value: this.oldValue}, this.property)}
+	}
+	if isEntryDelete(this.newValue) {
+		if this.property.Node().IsSlice && this.oldValue != nil {
+			if _, ok := this.property.Key().(int); ok {
+				return []*Change{NewChange(nil, properties.SliceInsert{Value: this.oldValue}, this.property)}
+			}
+			//A primary keyed element is re-appended and moved back to its position.
+			return []*Change{NewChange(this.newValue, this.oldValue, this.property),
+				NewChange(nil, properties.MoveTo(this.index), this.property)}
+		}
+		return []*Change{NewChange(this.newValue, this.oldValue, this.property)}
+	}
+	//A new map entry or a slice element that was appended is undone by deleting it.
+	if this.oldValue == nil && this.property.Key() != nil &&
+		(this.property.Node().IsMap || this.property.Node().IsSlice) {
+		return []*Change{NewChange(this.newValue, properties.EntryDelete{}, this.property)}
+	}
+	return []*Change{NewChange(this.newValue, this.oldValue, this.property)}
+}
+
+// Inverse returns the changes that undo the given changes, in the order they should be applied.
+func Inverse(changes []*Change) []*Change {
+	result := make([]*Change, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		result = append(result, changes[i].Inverse()...)
+	}
+	return result
+}
+
+// Revert applies the inverse of the changes on any, restoring it to its state before the changes.
+func Revert(any interface{}, changes []*Change) error {
+	for _, change := range Inverse(changes) {
+		_, _, err := change.property.Set(any, change.newValue)
+		if err != nil {
+			return errors.New("Failed to revert " + change.PropertyId() + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// isEntryDelete returns true for the ifs.Deleted_Entry string the Updater emits, so the changes serialize,
+// and for the EntryDelete operation value.
+func isEntryDelete(value interface{}) bool {
+	if str, ok := value.(string); ok {
+		return str == ifs.Deleted_Entry
+	}
+	_, ok := value.(properties.EntryDelete)
+	return ok
+}
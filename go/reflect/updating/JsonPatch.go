@@ -0,0 +1,395 @@
+package updating
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	strings2 "github.com/saichler/l8utils/go/utils/strings"
+)
+
+const (
+	JsonPatchAdd     = "add"
+	JsonPatchRemove  = "remove"
+	JsonPatchReplace = "replace"
+	JsonPatchMove    = "move"
+)
+
+// JsonPatchOperation is a single RFC 6902 operation.
+type JsonPatchOperation struct {
+	Op    string      `json:"op"`
+	From  string      `json:"from,omitempty"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	From  string          `json:"from"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ToJsonPatch converts the changes to an RFC 6902 JSON Patch document.
+func ToJsonPatch(changes []*Change) ([]byte, error) {
+	operations, err := JsonPatchOperations(changes)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(operations)
+}
+
+// JsonPatchOperations converts the changes to RFC 6902 operations.
+func JsonPatchOperations(changes []*Change) ([]*JsonPatchOperation, error) {
+	result := make([]*JsonPatchOperation, 0, len(changes))
+	for _, change := range changes {
+		path, err := JsonPointer(change.property)
+		if err != nil {
+			return nil, err
+		}
+		if insert, ok := change.newValue.(properties.SliceInsert); ok {
+			result = append(result, &JsonPatchOperation{Op: JsonPatchAdd, Path: path, Value: insert.Value})
+		} else if _, ok := change.newValue.(properties.SliceRemove); ok {
+			result = append(result, &JsonPatchOperation{Op: JsonPatchRemove, Path: path})
+		} else if move, ok := change.newValue.(properties.MoveTo); ok {
+			to := path[:strings.LastIndex(path, "/")+1] + strconv.Itoa(int(move))
+			result = append(result, &JsonPatchOperation{Op: JsonPatchMove, From: path, Path: to})
+		} else if isEntryDelete(change.newValue) {
+			result = append(result, &JsonPatchOperation{Op: JsonPatchRemove, Path: path})
+		} else if change.newValue == nil {
+			result = append(result, &JsonPatchOperation{Op: JsonPatchRemove, Path: path})
+		} else if change.oldValue == nil {
+			result = append(result, &JsonPatchOperation{Op: JsonPatchAdd, Path: path, Value: change.newValue})
+		} else {
+			result = append(result, &JsonPatchOperation{Op: JsonPatchReplace, Path: path, Value: change.newValue})
+		}
+	}
+	return result, nil
+}
+
+// JsonPointer returns the RFC 6901 JSON Pointer of the property, e.g. "testproto.mymap<key>.field" is "/mymap/key/field".
+// A field is pointed to by its json name when it has a json tag.
+// A primary keyed slice element is pointed to by its index, so its property must have its index set, as the Updater does.
+func JsonPointer(property *properties.Property) (string, error) {
+	tokens := make([]string, 0)
+	for property != nil {
+		parent, _ := property.Parent().(*properties.Property)
+		if parent == nil {
+			break
+		}
+		if property.Key() != nil {
+			token, err := jsonPointerKey(property)
+			if err != nil {
+				return "", err
+			}
+			tokens = append(tokens, token)
+		}
+		tokens = append(tokens, jsonPointerName(property.Node()))
+		property = parent
+	}
+	if len(tokens) == 0 {
+		return "", errors.New("cannot create a json pointer to the root element")
+	}
+	buff := strings2.New()
+	for i := len(tokens) - 1; i >= 0; i-- {
+		buff.Add("/")
+		buff.Add(escapeJsonPointer(tokens[i]))
+	}
+	return buff.String(), nil
+}
+
+// jsonPointerName is the json name of the field node, from its json tag, or its lowercased field name.
+func jsonPointerName(node *l8reflect.L8Node) string {
+	name := helping.DecoratorName(node, helping.JsonNameDecorator)
+	if name != "" {
+		return name
+	}
+	return strings.ToLower(node.FieldName)
+}
+
+func jsonPointerKey(property *properties.Property) (string, error) {
+	if _, ok := property.Key().(int); ok || !property.Node().IsSlice {
+		return strings2.New().StringOf(property.Key()), nil
+	}
+	index, ok := property.Index()
+	if !ok {
+		pid, _ := property.PropertyId()
+		return "", errors.New("cannot create a json pointer to " + pid + ", the index of the element is unknown")
+	}
+	return strconv.Itoa(index), nil
+}
+
+// ParseJsonPatch parses an RFC 6902 JSON Patch document into changes of the given root type.
+// The "-" array index is not supported, as it can only be resolved against an instance, see ApplyJsonPatch.
+func ParseJsonPatch(typeName string, patch []byte, resources ifs.IResources) ([]*Change, error) {
+	operations, err := unmarshalJsonPatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := resources.Introspector().Node(typeName)
+	if !ok {
+		return nil, errors.New("cannot find node for type " + typeName + ", please register it")
+	}
+	result := make([]*Change, 0, len(operations))
+	for _, operation := range operations {
+		change, err := jsonPatchChange(root, nil, operation, resources)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, change)
+	}
+	return result, nil
+}
+
+// ApplyJsonPatch applies an RFC 6902 JSON Patch document on any, using properties.Property.Set,
+// and returns the applied changes.
+func ApplyJsonPatch(any interface{}, patch []byte, resources ifs.IResources) ([]*Change, error) {
+	operations, err := unmarshalJsonPatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.ValueOf(any)
+	if !value.IsValid() || value.Kind() != reflect.Ptr || value.IsNil() {
+		return nil, errors.New("json patch can only be applied on a non nil pointer")
+	}
+	root, ok := resources.Introspector().Node(value.Elem().Type().Name())
+	if !ok {
+		return nil, errors.New("cannot find node for type " + value.Elem().Type().Name() + ", please register it")
+	}
+	result := make([]*Change, 0, len(operations))
+	for _, operation := range operations {
+		change, err := jsonPatchChange(root, any, operation, resources)
+		if err != nil {
+			return result, err
+		}
+		_, _, err = change.property.Set(any, change.newValue)
+		if err != nil {
+			return result, errors.New("Failed to apply " + operation.Op + " " + operation.Path + ": " + err.Error())
+		}
+		result = append(result, change)
+	}
+	return result, nil
+}
+
+func unmarshalJsonPatch(patch []byte) ([]*jsonPatchOperation, error) {
+	operations := make([]*jsonPatchOperation, 0)
+	err := json.Unmarshal(patch, &operations)
+	if err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+func jsonPatchChange(root *l8reflect.L8Node, any interface{}, operation *jsonPatchOperation, resources ifs.IResources) (*Change, error) {
+	switch operation.Op {
+	case JsonPatchAdd, JsonPatchRemove, JsonPatchReplace:
+	case JsonPatchMove:
+		return jsonPatchMove(root, any, operation, resources)
+	default:
+		return nil, errors.New("unsupported json patch operation " + operation.Op)
+	}
+	property, err := jsonPatchProperty(root, any, operation.Path, operation, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if operation.Op == JsonPatchRemove {
+		if _, ok := property.Key().(int); ok && property.Node().IsSlice {
+			return NewChange(nil, properties.SliceRemove{}, property), nil
+		}
+		if property.Key() != nil {
+			return NewChange(nil, properties.EntryDelete{}, property), nil
+		}
+		typ, err := jsonPatchType(property, resources)
+		if err != nil {
+			return nil, err
+		}
+		if typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Map || typ.Kind() == reflect.Slice {
+			return NewChange(nil, nil, property), nil
+		}
+		return NewChange(nil, reflect.Zero(typ).Interface(), property), nil
+	}
+
+	typ, err := jsonPatchType(property, resources)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.New(typ)
+	err = json.Unmarshal(operation.Value, value.Interface())
+	if err != nil {
+		return nil, errors.New("invalid value for " + operation.Path + ": " + err.Error())
+	}
+	//adding a slice element inserts it at the index, shifting the following elements
+	if _, ok := property.Key().(int); ok && property.Node().IsSlice && operation.Op == JsonPatchAdd {
+		return NewChange(nil, properties.SliceInsert{Value: value.Elem().Interface()}, property), nil
+	}
+	return NewChange(nil, value.Elem().Interface(), property), nil
+}
+
+func jsonPatchProperty(root *l8reflect.L8Node, any interface{}, pointer string, operation *jsonPatchOperation,
+	resources ifs.IResources) (*properties.Property, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("invalid json pointer " + pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	node := root
+	property := properties.NewProperty(root, nil, nil, nil, resources)
+	for i := 0; i < len(tokens); i++ {
+		attr := attributeByName(node, unescapeJsonPointer(tokens[i]))
+		if attr == nil {
+			return nil, errors.New("unknown attribute " + tokens[i] + " in " + pointer)
+		}
+		var key interface{}
+		if (attr.IsMap || attr.IsSlice) && i+1 < len(tokens) {
+			i++
+			k, err := jsonPatchKey(attr, property, any, unescapeJsonPointer(tokens[i]), operation, resources)
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		}
+		property = properties.NewProperty(attr, property, key, nil, resources)
+		node = attr
+	}
+	return property, nil
+}
+
+// jsonPatchMove moves a slice element to another index of its slice, the only move that is supported.
+func jsonPatchMove(root *l8reflect.L8Node, any interface{}, operation *jsonPatchOperation, resources ifs.IResources) (*Change, error) {
+	from, err := jsonPatchProperty(root, any, operation.From, operation, resources)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := from.Key().(int)
+	fromEnd := strings.LastIndex(operation.From, "/")
+	toEnd := strings.LastIndex(operation.Path, "/")
+	if !ok || !from.Node().IsSlice || toEnd == -1 || operation.From[:fromEnd] != operation.Path[:toEnd] {
+		return nil, errors.New("only moving a slice element within its slice is supported: " + operation.From + " to " + operation.Path)
+	}
+	to, err := strconv.Atoi(operation.Path[toEnd+1:])
+	if err != nil {
+		return nil, errors.New("invalid slice index in " + operation.Path)
+	}
+	return NewChange(nil, properties.MoveTo(to), from), nil
+}
+
+func jsonPatchKey(node *l8reflect.L8Node, parent *properties.Property, any interface{}, token string,
+	operation *jsonPatchOperation, resources ifs.IResources) (interface{}, error) {
+	if node.IsMap {
+		info, err := resources.Registry().Info(node.KeyTypeName)
+		if err != nil {
+			return nil, err
+		}
+		return keyOf(token, info.Type())
+	}
+	if token != "-" {
+		index, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, errors.New("invalid slice index " + token + " in " + operation.Path)
+		}
+		if any != nil {
+			size := sliceLen(node, parent, any, resources)
+			if index > size || (index == size && operation.Op != JsonPatchAdd) {
+				return nil, errors.New("slice index " + token + " is out of range: " + operation.Path)
+			}
+		}
+		return index, nil
+	}
+	if any == nil || operation.Op != JsonPatchAdd {
+		return nil, errors.New("the \"-\" index requires an add operation on an instance: " + operation.Path)
+	}
+	return sliceLen(node, parent, any, resources), nil
+}
+
+func sliceLen(node *l8reflect.L8Node, parent *properties.Property, any interface{}, resources ifs.IResources) int {
+	property := properties.NewProperty(node, parent, nil, nil, resources)
+	values := property.GetValue(reflect.ValueOf(any))
+	if len(values) != 1 || values[0].Kind() != reflect.Slice {
+		return 0
+	}
+	return values[0].Len()
+}
+
+func jsonPatchType(property *properties.Property, resources ifs.IResources) (reflect.Type, error) {
+	node := property.Node()
+	info, err := resources.Registry().Info(node.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	elem := info.Type()
+	if node.IsStruct {
+		elem = reflect.PointerTo(elem)
+	}
+	if property.Key() != nil {
+		return elem, nil
+	}
+	if node.IsMap {
+		kInfo, err := resources.Registry().Info(node.KeyTypeName)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(kInfo.Type(), elem), nil
+	}
+	if node.IsSlice {
+		return reflect.SliceOf(elem), nil
+	}
+	return elem, nil
+}
+
+func attributeByName(node *l8reflect.L8Node, name string) *l8reflect.L8Node {
+	if helping.IsLeaf(node) {
+		return nil
+	}
+	return helping.AttributeByName(node, name)
+}
+
+func keyOf(token string, typ reflect.Type) (interface{}, error) {
+	key := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		key.SetString(token)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		key.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(token, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		key.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, err
+		}
+		key.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(token)
+		if err != nil {
+			return nil, err
+		}
+		key.SetBool(b)
+	default:
+		return nil, errors.New("unsupported map key kind " + typ.Kind().String())
+	}
+	return key.Interface(), nil
+}
+
+func escapeJsonPointer(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+func unescapeJsonPointer(token string) string {
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
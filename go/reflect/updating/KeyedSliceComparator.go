@@ -0,0 +1,177 @@
+package updating
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+// keyedSliceUpdate aligns the old and new slice elements by their primary key, rather than by their index,
+// emitting insert, delete & modify changes of the elements and moves when their order has changed.
+func keyedSliceUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	registry := updates.resources.Registry()
+	parent := instance.Parent().(*properties.Property)
+	oldKeys, oldElems, _ := sliceKeys(node, oldValue, registry)
+	newKeys, newElems, _ := sliceKeys(node, newValue, registry)
+
+	for _, key := range newKeys {
+		oldElem, ok := oldElems[key]
+		newElem := newElems[key]
+		if !ok {
+			continue
+		}
+		subProperty := properties.NewProperty(node, parent, key, newElem.Interface(), updates.resources)
+		//the elements are modified before any of them is deleted, inserted or moved
+		subProperty.SetIndex(indexOf(oldKeys, key))
+		if updates.isEqual(subProperty, oldElem, newElem) {
+			continue
+		}
+		err := structUpdate(subProperty, node, oldElem.Elem(), newElem.Elem(), updates)
+		if err != nil {
+			return err
+		}
+	}
+
+	//current is the order of the elements, as the changes are applied one after the other
+	current := make([]interface{}, 0, len(oldKeys))
+	for _, key := range oldKeys {
+		if _, ok := newElems[key]; ok || !updates.newItemIsFull {
+			current = append(current, key)
+			continue
+		}
+		subProperty := properties.NewProperty(node, parent, key, nil, updates.resources)
+		subProperty.SetIndex(len(current))
+		updates.addUpdate(subProperty, oldElems[key].Interface(), ifs.Deleted_Entry)
+		updates.changes[len(updates.changes)-1].index = len(current)
+	}
+
+	for _, key := range newKeys {
+		if _, ok := oldElems[key]; ok {
+			continue
+		}
+		newElem := newElems[key]
+		subProperty := properties.NewProperty(node, parent, key, newElem.Interface(), updates.resources)
+		subProperty.SetIndex(len(current))
+		updates.addUpdate(subProperty, nil, newElem.Interface())
+		current = append(current, key)
+	}
+
+	//The order only matters when the new slice is the full slice
+	if updates.newItemIsFull {
+		stays := longestOrdered(current, newKeys)
+		for to, key := range newKeys {
+			if stays[key] {
+				continue
+			}
+			//the element is moved right after the element before it in the new order, which is already in place
+			from := indexOf(current, key)
+			current = append(current[:from], current[from+1:]...)
+			at := 0
+			if to > 0 {
+				at = indexOf(current, newKeys[to-1]) + 1
+			}
+			current = append(current[:at], append([]interface{}{key}, current[at:]...)...)
+			if from == at {
+				continue
+			}
+			subProperty := properties.NewProperty(node, parent, key, nil, updates.resources)
+			subProperty.SetIndex(from)
+			updates.addUpdate(subProperty, properties.MoveTo(from), properties.MoveTo(at))
+		}
+	}
+
+	newSlice := reflect.MakeSlice(oldValue.Type(), 0, len(current))
+	for _, key := range current {
+		elem, ok := oldElems[key]
+		if !ok {
+			elem = newElems[key]
+		}
+		newSlice = reflect.Append(newSlice, elem)
+	}
+	updates.set(oldValue, newSlice)
+	return nil
+}
+
+func hasPrimaryKey(node *l8reflect.L8Node, registry ifs.IRegistry) bool {
+	if node.Decorators == nil {
+		return false
+	}
+	_, ok := node.Decorators[int32(l8reflect.L8DecoratorType_Primary)]
+	if !ok {
+		return false
+	}
+	return len(helping.PrimaryDecoratorFields(node, registry)) > 0
+}
+
+// uniqueKeys returns true if the elements of the slice can be aligned by their primary key.
+func uniqueKeys(node *l8reflect.L8Node, value reflect.Value, registry ifs.IRegistry) bool {
+	_, _, ok := sliceKeys(node, value, registry)
+	return ok
+}
+
+// sliceKeys returns the primary keys of the slice elements in their order,
+// and false if an element is nil or has the key of a previous element.
+func sliceKeys(node *l8reflect.L8Node, value reflect.Value, registry ifs.IRegistry) ([]interface{}, map[interface{}]reflect.Value, bool) {
+	keys := make([]interface{}, 0, value.Len())
+	elems := make(map[interface{}]reflect.Value)
+	for i := 0; i < value.Len(); i++ {
+		elem := value.Index(i)
+		if elem.IsNil() {
+			return nil, nil, false
+		}
+		key := helping.PrimaryDecorator(node, elem.Elem(), registry)
+		if _, ok := elems[key]; ok {
+			return nil, nil, false
+		}
+		keys = append(keys, key)
+		elems[key] = elem
+	}
+	return keys, elems, true
+}
+
+// longestOrdered returns the keys of the longest subsequence of current that is already in the order of newKeys,
+// they stay in place and only the other keys are moved.
+func longestOrdered(current, newKeys []interface{}) map[interface{}]bool {
+	positions := make(map[interface{}]int, len(newKeys))
+	for i, key := range newKeys {
+		positions[key] = i
+	}
+	//tails[l] is the index in current of the smallest last position of an ordered subsequence of length l+1
+	tails := make([]int, 0, len(current))
+	prev := make([]int, len(current))
+	for i, key := range current {
+		pos := positions[key]
+		l := sort.Search(len(tails), func(j int) bool { return positions[current[tails[j]]] >= pos })
+		prev[i] = -1
+		if l > 0 {
+			prev[i] = tails[l-1]
+		}
+		if l == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[l] = i
+		}
+	}
+	stays := make(map[interface{}]bool, len(tails))
+	if len(tails) == 0 {
+		return stays
+	}
+	for i := tails[len(tails)-1]; i != -1; i = prev[i] {
+		stays[current[i]] = true
+	}
+	return stays
+}
+
+// indexOf compares the keys with reflect.DeepEqual, as a key that is not comparable would panic with ==.
+func indexOf(keys []interface{}, key interface{}) int {
+	for i, k := range keys {
+		if reflect.DeepEqual(k, key) {
+			return i
+		}
+	}
+	return -1
+}
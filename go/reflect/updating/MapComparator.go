@@ -5,21 +5,25 @@ import (
 
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
 	"github.com/saichler/l8reflect/go/reflect/properties"
 )
 
 func mapUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	if updates.nilIsEmpty(oldValue, newValue) || updates.unchanged(instance) {
+		return nil
+	}
 	if oldValue.IsNil() && newValue.IsNil() {
 		return nil
 	}
 	if oldValue.IsNil() && !newValue.IsNil() {
 		updates.addUpdate(instance, nil, newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 		return nil
 	}
 	if !oldValue.IsNil() && newValue.IsNil() && updates.nilIsValid {
-		updates.addUpdate(instance, oldValue, nil)
-		oldValue.Set(newValue)
+		updates.addUpdate(instance, oldValue.Interface(), nil)
+		updates.set(oldValue, newValue)
 		return nil
 	}
 
@@ -32,22 +36,21 @@ func mapUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue,
 			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), key.Interface(),
 				newKeyValue.Interface(), updates.resources)
 			updates.addUpdate(subProperty, nil, newKeyValue.Interface())
-			oldValue.SetMapIndex(key, newKeyValue)
+			updates.setMapIndex(oldValue, key, newKeyValue)
 			continue
 		}
 
-		if !node.IsStruct {
-			if deepEqual.Equal(oldKeyValue.Interface(), newKeyValue.Interface()) {
+		subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), key.Interface(), newKeyValue.Interface(), updates.resources)
+		if !node.IsStruct || introspecting.NoNestedInspection(node) {
+			if updates.isEqual(subProperty, oldKeyValue, newKeyValue) {
 				continue
 			}
-			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), key.Interface(), newKeyValue.Interface(), updates.resources)
-			updates.addUpdate(subProperty, nil, newKeyValue.Interface())
-			oldValue.SetMapIndex(key, newKeyValue)
+			updates.addUpdate(subProperty, oldKeyValue.Interface(), newKeyValue.Interface())
+			updates.setMapIndex(oldValue, key, newKeyValue)
 		} else if oldKeyValue.IsValid() && newKeyValue.IsValid() {
-			if deepEqual.Equal(oldKeyValue.Interface(), newKeyValue.Interface()) {
+			if updates.isEqual(subProperty, oldKeyValue, newKeyValue) {
 				continue
 			}
-			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), key.Interface(), newKeyValue.Interface(), updates.resources)
 			err := structUpdate(subProperty, node, oldKeyValue.Elem(), newKeyValue.Elem(), updates)
 			if err != nil {
 				return err
@@ -63,7 +66,7 @@ func mapUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue,
 			if !newKeyValue.IsValid() {
 				subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), key.Interface(), nil, updates.resources)
 				updates.addUpdate(subProperty, oldKeyValue.Interface(), ifs.Deleted_Entry)
-				oldValue.SetMapIndex(key, reflect.Value{})
+				updates.setMapIndex(oldValue, key, reflect.Value{})
 			}
 		}
 	}
@@ -0,0 +1,239 @@
+package updating
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+type MergePolicy int
+
+const (
+	MergeOurs MergePolicy = iota
+	MergeTheirs
+	MergeCallback
+)
+
+// MergeConflict is a set of overlapping changes, made by both sides to the same leaf, map key or slice index.
+type MergeConflict struct {
+	PropertyId string
+	Ours       []*Change
+	Theirs     []*Change
+}
+
+// MergeResolver decides which side wins a conflict, it should return either MergeOurs or MergeTheirs.
+type MergeResolver func(conflict *MergeConflict) MergePolicy
+
+type Merger struct {
+	resources ifs.IResources
+	policy    MergePolicy
+	resolver  MergeResolver
+	cloner    *cloning.Cloner
+}
+
+func NewMerger(resources ifs.IResources, policy MergePolicy) *Merger {
+	merger := &Merger{}
+	merger.resources = resources
+	merger.policy = policy
+	merger.cloner = cloning.NewCloner()
+	return merger
+}
+
+// SetResolver sets the callback that resolves conflicts and switches the policy to MergeCallback.
+func (this *Merger) SetResolver(resolver MergeResolver) {
+	this.resolver = resolver
+	this.policy = MergeCallback
+}
+
+// Merge does a three-way merge of ours and theirs, both derived from base.
+// Changes that do not overlap are applied automatically, overlapping changes are resolved by the policy
+// and reported as conflicts. Base, ours and theirs are not modified.
+func (this *Merger) Merge(base, ours, theirs interface{}) (interface{}, []*MergeConflict, error) {
+	differ := NewUpdater(this.resources, true, true)
+	oursChanges, err := differ.Diff(base, ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirsChanges, err := differ.Diff(base, theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//group overlapping changes, ours are indexed first and theirs after them
+	groups := make([]int, len(oursChanges)+len(theirsChanges))
+	for i := range groups {
+		groups[i] = i
+	}
+	for i, o := range oursChanges {
+		for j, t := range theirsChanges {
+			if overlap(o, t) {
+				union(groups, i, len(oursChanges)+j)
+			}
+		}
+	}
+
+	conflicts := make([]*MergeConflict, 0)
+	byGroup := make(map[int]*MergeConflict)
+	for i := range groups {
+		root := find(groups, i)
+		conflict, ok := byGroup[root]
+		if !ok {
+			conflict = &MergeConflict{}
+			byGroup[root] = conflict
+		}
+		if i < len(oursChanges) {
+			conflict.Ours = append(conflict.Ours, oursChanges[i])
+		} else {
+			conflict.Theirs = append(conflict.Theirs, theirsChanges[i-len(oursChanges)])
+		}
+	}
+
+	apply := make(map[*Change]bool)
+	for i := range groups {
+		conflict := byGroup[find(groups, i)]
+		if len(conflict.Theirs) == 0 || len(conflict.Ours) == 0 || sameChanges(conflict) {
+			if i < len(oursChanges) {
+				apply[oursChanges[i]] = true
+			} else if len(conflict.Ours) == 0 {
+				apply[theirsChanges[i-len(oursChanges)]] = true
+			}
+			continue
+		}
+		if conflict.PropertyId == "" {
+			conflict.PropertyId = outerPropertyId(conflict)
+			conflicts = append(conflicts, conflict)
+			winners := conflict.Ours
+			if this.resolve(conflict) == MergeTheirs {
+				winners = conflict.Theirs
+			}
+			for _, change := range winners {
+				apply[change] = true
+			}
+		}
+	}
+
+	merged := this.cloner.Clone(base)
+	for _, changes := range [][]*Change{oursChanges, theirsChanges} {
+		for _, change := range changes {
+			if !apply[change] {
+				continue
+			}
+			_, _, err = change.property.Set(merged, this.cloner.Clone(change.newValue))
+			if err != nil {
+				return nil, conflicts, errors.New("Failed to merge " + change.PropertyId() + ": " + err.Error())
+			}
+		}
+	}
+	return merged, conflicts, nil
+}
+
+func (this *Merger) resolve(conflict *MergeConflict) MergePolicy {
+	if this.policy == MergeCallback && this.resolver != nil {
+		return this.resolver(conflict)
+	}
+	return this.policy
+}
+
+// overlap returns true if both changes touch the same leaf, map key or slice index,
+// or one of them changes a subtree containing the other.
+func overlap(a, b *Change) bool {
+	aId := a.PropertyId()
+	bId := b.PropertyId()
+	if aId == bId || isSubProperty(aId, bId) || isSubProperty(bId, aId) {
+		return true
+	}
+	return truncates(a, b) || truncates(b, a)
+}
+
+func isSubProperty(id, subId string) bool {
+	if !strings.HasPrefix(subId, id) || len(subId) == len(id) {
+		return false
+	}
+	next := subId[len(id)]
+	return next == '.' || next == '<'
+}
+
+// truncates returns true if a is a slice element removal that removes, or shifts, the element other is changing.
+func truncates(a, other *Change) bool {
+	_, removes := a.newValue.(properties.SliceRemove)
+	if (!removes && !isEntryDelete(a.newValue)) || !a.property.Node().IsSlice {
+		return false
+	}
+	index, ok := a.property.Key().(int)
+	if !ok {
+		return false
+	}
+	parent, _ := a.property.Parent().(*properties.Property)
+	if parent == nil {
+		return false
+	}
+	parentId, _ := parent.PropertyId()
+	for p := other.property; p != nil; {
+		pParent, _ := p.Parent().(*properties.Property)
+		if pParent == nil {
+			return false
+		}
+		if p.Node() == a.property.Node() {
+			pParentId, _ := pParent.PropertyId()
+			pIndex, ok := p.Key().(int)
+			return ok && pParentId == parentId && pIndex >= index
+		}
+		p = pParent
+	}
+	return false
+}
+
+// sameChanges returns true if both sides made the same changes. Each of ours is matched with the first
+// unmatched change of theirs with the same property id, so changes to the same id are compared in order.
+func sameChanges(conflict *MergeConflict) bool {
+	if len(conflict.Ours) != len(conflict.Theirs) {
+		return false
+	}
+	matched := make([]bool, len(conflict.Theirs))
+	for _, o := range conflict.Ours {
+		found := false
+		for j, t := range conflict.Theirs {
+			if matched[j] || o.PropertyId() != t.PropertyId() {
+				continue
+			}
+			if !deepEqual.Equal(o.newValue, t.newValue) {
+				return false
+			}
+			matched[j] = true
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func outerPropertyId(conflict *MergeConflict) string {
+	result := ""
+	for _, changes := range [][]*Change{conflict.Ours, conflict.Theirs} {
+		for _, change := range changes {
+			id := change.PropertyId()
+			if result == "" || len(id) < len(result) {
+				result = id
+			}
+		}
+	}
+	return result
+}
+
+func find(groups []int, i int) int {
+	for groups[i] != i {
+		groups[i] = groups[groups[i]]
+		i = groups[i]
+	}
+	return i
+}
+
+func union(groups []int, a, b int) {
+	groups[find(groups, a)] = find(groups, b)
+}
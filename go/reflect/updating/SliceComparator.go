@@ -5,23 +5,43 @@ import (
 
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
 	"github.com/saichler/l8reflect/go/reflect/properties"
 )
 
 func sliceUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	if updates.nilIsEmpty(oldValue, newValue) || updates.unchanged(instance) {
+		return nil
+	}
 	if oldValue.IsNil() && newValue.IsNil() {
 		return nil
 	}
 	if oldValue.IsNil() && !newValue.IsNil() {
 		updates.addUpdate(instance, nil, newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 		return nil
 	}
 	if !oldValue.IsNil() && newValue.IsNil() && updates.nilIsValid {
-		updates.addUpdate(instance, oldValue, nil)
-		oldValue.Set(newValue)
+		updates.addUpdate(instance, oldValue.Interface(), nil)
+		updates.set(oldValue, newValue)
+		return nil
+	}
+	if oldValue.Type().Elem().Kind() == reflect.Uint8 {
+		return bytesUpdate(instance, node, oldValue, newValue, updates)
+	}
+	//the elements are only re-ordered
+	if updates.options() != nil && updates.options().UnorderedSlices && updates.isEqual(instance, oldValue, newValue) {
 		return nil
 	}
+	//a slice with a nil element or a duplicate primary key is diffed by index, so no element is lost
+	registry := updates.resources.Registry()
+	if node.IsStruct && !introspecting.NoNestedInspection(node) && hasPrimaryKey(node, registry) &&
+		uniqueKeys(node, oldValue, registry) && uniqueKeys(node, newValue, registry) {
+		return keyedSliceUpdate(instance, node, oldValue, newValue, updates)
+	}
+	if updates.editScript && updates.newItemIsFull {
+		return sliceEditUpdate(instance, node, oldValue, newValue, updates)
+	}
 
 	size := newValue.Len()
 	if size > oldValue.Len() {
@@ -31,25 +51,25 @@ func sliceUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue
 	for i := 0; i < size; i++ {
 		oldIndexValue := oldValue.Index(i)
 		newIndexValue := newValue.Index(i)
-		if !node.IsStruct {
-			if oldIndexValue.IsValid() && deepEqual.Equal(oldIndexValue.Interface(), newIndexValue.Interface()) {
-				continue
-			}
+		if !node.IsStruct || introspecting.NoNestedInspection(node) {
 			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), i,
 				newIndexValue.Interface(), updates.resources)
-			updates.addUpdate(subProperty, nil, newIndexValue.Interface())
-			oldIndexValue.Set(newIndexValue)
-		} else if !oldIndexValue.IsValid() || oldIndexValue.IsNil() {
+			if oldIndexValue.IsValid() && updates.isEqual(subProperty, oldIndexValue, newIndexValue) {
+				continue
+			}
+			updates.addUpdate(subProperty, oldIndexValue.Interface(), newIndexValue.Interface())
+			updates.set(oldIndexValue, newIndexValue)
+		} else if !oldIndexValue.IsValid() || oldIndexValue.IsNil() || newIndexValue.IsNil() {
 			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property),
 				i, newIndexValue.Interface(), updates.resources)
-			updates.addUpdate(subProperty, nil, newIndexValue.Interface())
-			oldIndexValue.Set(newIndexValue)
+			updates.addUpdate(subProperty, oldIndexValue.Interface(), newIndexValue.Interface())
+			updates.set(oldIndexValue, newIndexValue)
 		} else if oldIndexValue.IsValid() && newIndexValue.IsValid() {
-			if deepEqual.Equal(oldIndexValue.Interface(), newIndexValue.Interface()) {
-				continue
-			}
 			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property),
 				i, newIndexValue.Interface(), updates.resources)
+			if updates.isEqual(subProperty, oldIndexValue, newIndexValue) {
+				continue
+			}
 			err := structUpdate(subProperty, node, oldIndexValue.Elem(), newIndexValue.Elem(), updates)
 			if err != nil {
 				return err
@@ -73,12 +93,21 @@ func sliceUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue
 		for i := 0; i < size; i++ {
 			newSlice.Index(i).Set(oldValue.Index(i))
 		}
-		subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), size,
-			nil, updates.resources)
-		updates.addUpdate(subProperty, nil, ifs.Deleted_Entry)
-		oldValue.Set(newSlice)
+		//the truncation removes the elements one by one from the last one, each truncating the slice at its index,
+		//with the removed element as the old value so it can be reverted
+		for i := oldValue.Len() - 1; i >= size; i-- {
+			subProperty := properties.NewProperty(node, instance.Parent().(*properties.Property), i,
+				nil, updates.resources)
+			updates.addUpdate(subProperty, oldValue.Index(i).Interface(), ifs.Deleted_Entry)
+		}
+		updates.set(oldValue, newSlice)
 	} else if newValue.Len() > oldValue.Len() {
-		newSlice := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(vInfo.Type())), newValue.Len(), newValue.Len())
+		var newSlice reflect.Value
+		if node.IsStruct {
+			newSlice = reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(vInfo.Type())), newValue.Len(), newValue.Len())
+		} else {
+			newSlice = reflect.MakeSlice(reflect.SliceOf(vInfo.Type()), newValue.Len(), newValue.Len())
+		}
 		for i := 0; i < size; i++ {
 			newSlice.Index(i).Set(oldValue.Index(i))
 		}
@@ -89,7 +118,7 @@ func sliceUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue
 				newV.Interface(), updates.resources)
 			updates.addUpdate(subProperty, nil, newV.Interface())
 		}
-		oldValue.Set(newSlice)
+		updates.set(oldValue, newSlice)
 	}
 
 	return nil
@@ -0,0 +1,117 @@
+package updating
+
+import (
+	"reflect"
+
+	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+const (
+	editKeep = iota
+	editRemove
+	editInsert
+)
+
+// sliceEditUpdate diffs the slices by their longest common subsequence of equal elements,
+// emitting the minimal sequence of element removes & inserts that turns the old slice into the new one.
+// The changes are applied one after the other, so each index is relative to the slice after the previous changes.
+func sliceEditUpdate(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
+	parent := instance.Parent().(*properties.Property)
+	script := editScript(instance, oldValue, newValue, updates)
+	newSlice := reflect.MakeSlice(oldValue.Type(), 0, newValue.Len())
+	index, o, n := 0, 0, 0
+	for _, op := range script {
+		switch op {
+		case editKeep:
+			newSlice = reflect.Append(newSlice, oldValue.Index(o))
+			index++
+			o++
+			n++
+		case editRemove:
+			subProperty := properties.NewProperty(node, parent, index, nil, updates.resources)
+			updates.addUpdate(subProperty, oldValue.Index(o).Interface(), properties.SliceRemove{})
+			o++
+		case editInsert:
+			elem := newValue.Index(n)
+			subProperty := properties.NewProperty(node, parent, index, elem.Interface(), updates.resources)
+			updates.addUpdate(subProperty, nil, properties.SliceInsert{Value: elem.Interface()})
+			newSlice = reflect.Append(newSlice, elem)
+			index++
+			n++
+		}
+	}
+	if !allKept(script) {
+		updates.set(oldValue, newSlice)
+	}
+	return nil
+}
+
+// editScript returns the keep, remove & insert operations of the longest common subsequence of both slices.
+func editScript(instance *properties.Property, oldValue, newValue reflect.Value, updates *Updater) []int {
+	oldLen := oldValue.Len()
+	newLen := newValue.Len()
+	//The common prefix & suffix are kept as is, so only the middle part needs the lcs table
+	prefix := 0
+	for prefix < oldLen && prefix < newLen && updates.isEqual(instance, oldValue.Index(prefix), newValue.Index(prefix)) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < oldLen-prefix && suffix < newLen-prefix &&
+		updates.isEqual(instance, oldValue.Index(oldLen-1-suffix), newValue.Index(newLen-1-suffix)) {
+		suffix++
+	}
+	rows := oldLen - prefix - suffix
+	cols := newLen - prefix - suffix
+
+	equal := make([][]bool, rows)
+	lcs := make([][]int, rows+1)
+	for i := range lcs {
+		lcs[i] = make([]int, cols+1)
+	}
+	for i := rows - 1; i >= 0; i-- {
+		equal[i] = make([]bool, cols)
+		for j := cols - 1; j >= 0; j-- {
+			equal[i][j] = updates.isEqual(instance, oldValue.Index(prefix+i), newValue.Index(prefix+j))
+			if equal[i][j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	script := make([]int, 0, oldLen+newLen)
+	for i := 0; i < prefix; i++ {
+		script = append(script, editKeep)
+	}
+	i, j := 0, 0
+	for i < rows || j < cols {
+		if i < rows && j < cols && equal[i][j] {
+			script = append(script, editKeep)
+			i++
+			j++
+		} else if j == cols || (i < rows && lcs[i+1][j] >= lcs[i][j+1]) {
+			script = append(script, editRemove)
+			i++
+		} else {
+			script = append(script, editInsert)
+			j++
+		}
+	}
+	for i := 0; i < suffix; i++ {
+		script = append(script, editKeep)
+	}
+	return script
+}
+
+func allKept(script []int) bool {
+	for _, op := range script {
+		if op != editKeep {
+			return false
+		}
+	}
+	return true
+}
@@ -5,29 +5,33 @@ import (
 	"reflect"
 
 	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
 	"github.com/saichler/l8reflect/go/reflect/properties"
 )
 
 func ptrUpdate(property *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
 	if oldValue.IsNil() && !newValue.IsNil() {
 		updates.addUpdate(property, nil, newValue.Interface())
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 		return nil
 	}
 	if !oldValue.IsNil() && newValue.IsNil() && updates.nilIsValid {
-		updates.addUpdate(property, oldValue, nil)
-		oldValue.Set(newValue)
+		updates.addUpdate(property, oldValue.Interface(), nil)
+		updates.set(oldValue, newValue)
 		return nil
 	}
 	if oldValue.IsNil() && newValue.IsNil() {
 		return nil
 	}
+	if introspecting.NoNestedInspection(node) {
+		return opaqueUpdate(property, oldValue, newValue, updates)
+	}
 	return update(property, node, oldValue.Elem(), newValue.Elem(), updates)
 }
 
 func structUpdate(property *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
 	if !oldValue.IsValid() && newValue.IsValid() {
-		oldValue.Set(newValue)
+		updates.set(oldValue, newValue)
 		updates.addUpdate(property, nil, newValue.Interface())
 		return nil
 	}
@@ -48,10 +52,19 @@ func structUpdate(property *properties.Property, node *l8reflect.L8Node, oldValu
 	if oldValue.Type().Name() != newValue.Type().Name() {
 		return errors.New("Mismatch type, old=" + oldValue.Type().Name() + ", new=" + newValue.Type().Name())
 	}
+	if updates.unchanged(property) {
+		return nil
+	}
+	if introspecting.NoNestedInspection(node) {
+		return opaqueUpdate(property, oldValue, newValue, updates)
+	}
 	for _, attr := range node.Attributes {
 		oldFldValue := oldValue.FieldByName(attr.FieldName)
 		newFldValue := newValue.FieldByName(attr.FieldName)
 		subInstance := properties.NewProperty(attr, property, nil, oldFldValue, updates.resources)
+		if updates.ignored(subInstance) {
+			continue
+		}
 		err := update(subInstance, attr, oldFldValue, newFldValue, updates)
 		if err != nil {
 			return err
@@ -59,3 +72,13 @@ func structUpdate(property *properties.Property, node *l8reflect.L8Node, oldValu
 	}
 	return nil
 }
+
+// opaqueUpdate replaces a NoNestedInspection value as a whole instead of walking its fields.
+func opaqueUpdate(property *properties.Property, oldValue, newValue reflect.Value, updates *Updater) error {
+	if updates.isEqual(property, oldValue, newValue) {
+		return nil
+	}
+	updates.addUpdate(property, oldValue.Interface(), newValue.Interface())
+	updates.set(oldValue, newValue)
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/saichler/l8types/go/ifs"
 	"github.com/saichler/l8types/go/types/l8reflect"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/hashing"
 	"github.com/saichler/l8reflect/go/reflect/helping"
 	"github.com/saichler/l8reflect/go/reflect/properties"
 )
@@ -15,6 +17,11 @@ type Updater struct {
 	resources     ifs.IResources
 	nilIsValid    bool
 	newItemIsFull bool
+	diffOnly      bool
+	editScript    bool
+	equal         *cloning.DeepEqual
+	oldHashes     *hashing.HashTree
+	newHashes     *hashing.HashTree
 }
 
 func NewUpdater(resources ifs.IResources, isNilValid, newItemIsFull bool) *Updater {
@@ -22,9 +29,31 @@ func NewUpdater(resources ifs.IResources, isNilValid, newItemIsFull bool) *Updat
 	upd.resources = resources
 	upd.nilIsValid = isNilValid
 	upd.newItemIsFull = newItemIsFull
+	upd.equal = deepEqual
 	return upd
 }
 
+// SetSliceEditScript enables diffing slices without a primary key as a minimal sequence of
+// element inserts & removes, rather than by index. It only applies when newItemIsFull is true.
+func (this *Updater) SetSliceEditScript(enabled bool) {
+	this.editScript = enabled
+}
+
+// SetEqualOptions sets the options that decide if a value is unchanged, e.g. ignored property ids,
+// a float epsilon or unordered slices, so volatile or re-ordered values don't produce changes.
+func (this *Updater) SetEqualOptions(options *cloning.EqualOptions) {
+	this.equal = cloning.NewDeepEqual()
+	this.equal.SetOptions(options)
+}
+
+// SetHashTrees sets the hash trees of the old & new instances of the next Update or Diff,
+// so subtrees with the same hash are skipped without comparing them.
+// The trees must be up to date with the instances, e.g. maintained by a replica.
+func (this *Updater) SetHashTrees(oldHashes, newHashes *hashing.HashTree) {
+	this.oldHashes = oldHashes
+	this.newHashes = newHashes
+}
+
 func (this *Updater) Changes() []*Change {
 	return this.changes
 }
@@ -50,6 +79,22 @@ func (this *Updater) Update(old, new interface{}) error {
 	return err
 }
 
+// Diff returns the changes between old and new, using the same comparators as Update,
+// without modifying either of them.
+func (this *Updater) Diff(old, new interface{}) ([]*Change, error) {
+	differ := NewUpdater(this.resources, this.nilIsValid, this.newItemIsFull)
+	differ.diffOnly = true
+	differ.editScript = this.editScript
+	differ.equal = this.equal
+	differ.oldHashes = this.oldHashes
+	differ.newHashes = this.newHashes
+	err := differ.Update(old, new)
+	if err != nil {
+		return nil, err
+	}
+	return differ.changes, nil
+}
+
 func update(instance *properties.Property, node *l8reflect.L8Node, oldValue, newValue reflect.Value, updates *Updater) error {
 	if !newValue.IsValid() {
 		return nil
@@ -75,3 +120,56 @@ func (this *Updater) addUpdate(prop *properties.Property, oldValue, newValue int
 	}
 	this.changes = append(this.changes, NewChange(oldValue, newValue, prop))
 }
+
+func (this *Updater) set(oldValue, newValue reflect.Value) {
+	if this.diffOnly {
+		return
+	}
+	oldValue.Set(newValue)
+}
+
+func (this *Updater) setMapIndex(mapValue, key, value reflect.Value) {
+	if this.diffOnly {
+		return
+	}
+	mapValue.SetMapIndex(key, value)
+}
+
+func (this *Updater) options() *cloning.EqualOptions {
+	return this.equal.Options()
+}
+
+// isEqual compares the values located at the property, so ignored property ids under it are matched.
+func (this *Updater) isEqual(property *properties.Property, oldValue, newValue reflect.Value) bool {
+	options := this.options()
+	if options == nil || len(options.IgnoreIds) == 0 {
+		return this.equal.Equal(oldValue.Interface(), newValue.Interface())
+	}
+	id, _ := property.PropertyId()
+	return this.equal.EqualAt(oldValue.Interface(), newValue.Interface(), id, helping.NodeCacheKey(property.Node()))
+}
+
+func (this *Updater) ignored(property *properties.Property) bool {
+	options := this.options()
+	if options == nil || len(options.IgnoreIds) == 0 {
+		return false
+	}
+	id, _ := property.PropertyId()
+	return options.Ignored(id, helping.NodeCacheKey(property.Node()))
+}
+
+// unchanged returns true if the hash trees show that the subtree of the property is the same on both sides.
+func (this *Updater) unchanged(property *properties.Property) bool {
+	if this.oldHashes == nil || this.newHashes == nil {
+		return false
+	}
+	id, _ := property.PropertyId()
+	oldTree := this.oldHashes.Find(id)
+	newTree := this.newHashes.Find(id)
+	return oldTree != nil && newTree != nil && oldTree.Hash == newTree.Hash
+}
+
+func (this *Updater) nilIsEmpty(oldValue, newValue reflect.Value) bool {
+	options := this.options()
+	return options != nil && options.NilIsEmpty && oldValue.Len() == 0 && newValue.Len() == 0
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+type AliasModel struct {
+	Values  []int32
+	Window  []int32
+	Subs    []*RevertSub
+	SubsTo  []*RevertSub
+	Map     map[string]int32
+	MapTo   map[string]int32
+	Sub     *RevertSub
+	Self    *AliasModel
+	Another []int32
+}
+
+func TestCloneKeepsAliases(t *testing.T) {
+	values := []int32{1, 2, 3, 4}
+	sub := &RevertSub{Name: "sub"}
+	model := &AliasModel{Values: values, Window: values[1:3], Subs: []*RevertSub{sub}, Map: map[string]int32{"a": 1},
+		Sub: sub, Another: []int32{1, 2, 3, 4}}
+	model.SubsTo = model.Subs
+	model.MapTo = model.Map
+	model.Self = model
+
+	clone, aliases := cloning.NewCloner().CloneReportAliases(model)
+	cloned := clone.(*AliasModel)
+	if len(cloned.Values) != 4 || len(cloned.Window) != 2 || cap(cloned.Window) != 3 || cloned.Window[0] != 2 || cloned.Sub.Name != "sub" {
+		log.Fail(t, "Expected the clone to have the same values")
+		return
+	}
+	cloned.Values[1] = 20
+	if cloned.Window[0] != 20 || values[1] != 2 {
+		log.Fail(t, "Expected the window to share the cloned backing array of values only")
+		return
+	}
+	cloned.Another[0] = 10
+	if cloned.Values[0] != 1 {
+		log.Fail(t, "Expected equal slices with different backing arrays to stay independent")
+		return
+	}
+	cloned.MapTo["b"] = 2
+	if len(cloned.Map) != 2 || len(model.Map) != 1 {
+		log.Fail(t, "Expected the aliased maps to be the same cloned map")
+		return
+	}
+	if &cloned.SubsTo[0] != &cloned.Subs[0] || cloned.Sub != cloned.Subs[0] || cloned.Sub == sub {
+		log.Fail(t, "Expected the aliased slices and pointers to be the same cloned ones")
+		return
+	}
+	if cloned.Self != cloned {
+		log.Fail(t, "Expected the self reference to point to the clone")
+		return
+	}
+	expected := map[string]bool{
+		"aliasmodel.window -> aliasmodel.values":  true,
+		"aliasmodel.substo -> aliasmodel.subs":    true,
+		"aliasmodel.mapto -> aliasmodel.map":      true,
+		"aliasmodel.sub -> aliasmodel.subs<{2}0>": true,
+		"aliasmodel.self -> aliasmodel":           true,
+	}
+	if len(aliases) != len(expected) {
+		log.Fail(t, "Expected ", len(expected), " aliases, got ", len(aliases), " ", aliases)
+		return
+	}
+	for _, alias := range aliases {
+		if !expected[alias.AliasPath+" -> "+alias.Path] {
+			log.Fail(t, "Unexpected alias ", alias.String())
+			return
+		}
+	}
+}
+
+func TestCloneKeepAliasesOnlyWhenAsked(t *testing.T) {
+	model := &AliasModel{Map: map[string]int32{"a": 1}, Values: []int32{1, 2}}
+	model.MapTo = model.Map
+	model.Window = model.Values
+	model.Self = model
+
+	cloned := cloning.NewCloner().Clone(model).(*AliasModel)
+	cloned.MapTo["b"] = 2
+	cloned.Window[0] = 10
+	if len(cloned.Map) != 1 || cloned.Values[0] != 1 || cloned.Self != cloned {
+		log.Fail(t, "Expected the slices and maps to be copied apart and the cycle to be kept")
+		return
+	}
+
+	cloner := cloning.NewCloner()
+	cloner.KeepAliases()
+	cloned = cloner.Clone(model).(*AliasModel)
+	cloned.MapTo["b"] = 2
+	cloned.Window[0] = 10
+	if len(cloned.Map) != 2 || cloned.Values[0] != 10 {
+		log.Fail(t, "Expected the aliased slices and maps to be shared in the clone")
+		return
+	}
+
+	//without the aliases, Clone allocates no more than the baseline cloner
+	m := utils.CreateTestModelInstance(1)
+	baseline := newBaselineCloner()
+	baselineAllocs := testing.AllocsPerRun(100, func() { baseline.Clone(m) })
+	plans := cloning.NewCloner()
+	plans.Clone(m)
+	planAllocs := testing.AllocsPerRun(100, func() { plans.Clone(m) })
+	if planAllocs > baselineAllocs {
+		log.Fail(t, "Expected Clone to allocate no more than the baseline, got ", planAllocs, " and ", baselineAllocs)
+		return
+	}
+}
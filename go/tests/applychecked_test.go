@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func TestApplyChecked(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := utils.CreateTestModelInstance(1)
+	aside.MyString2StringMap["a"] = "1"
+	aside.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	yside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	xside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyString = "checked"
+	delete(zside.MyString2StringMap, "a")
+	zside.MyString2StringMap["c"] = "3"
+	zside.MyString2ModelMap["b"].MyInt64 = 7
+	zside.MySingle = nil
+
+	upd := updating.NewUpdater(res, true, true)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+
+	for _, change := range upd.Changes() {
+		err = change.ApplyChecked(yside)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+	}
+	if !cloning.NewDeepEqual().Equal(yside, zside) {
+		log.Fail(t, "Expected checked apply to update the instance")
+		return
+	}
+
+	xside.MyString2ModelMap["b"].MyInt64 = 8
+	conflicts := 0
+	for _, change := range upd.Changes() {
+		err = change.ApplyChecked(xside)
+		conflict := &updating.ConflictError{}
+		if errors.As(err, &conflict) {
+			conflicts++
+			if conflict.PropertyId != "testproto.mystring2modelmap<{24}b>.myint64" {
+				log.Fail(t, "Unexpected conflict ", conflict.Error())
+				return
+			}
+		} else if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+	}
+	if conflicts != 1 || xside.MyString2ModelMap["b"].MyInt64 != 8 {
+		log.Fail(t, "Expected a single conflict that is not applied")
+		return
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func TestApplyBatchRollback(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := utils.CreateTestModelInstance(1)
+	aside.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	yside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	original := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyString = "batch"
+	zside.MyString2StringMap["c"] = "3"
+	zside.MyString2ModelMap["b"].MyInt64 = 7
+
+	upd := updating.NewUpdater(res, false, false)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+
+	prop, err := properties.PropertyOf("testproto.mystring2stringmap", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	changes := append(upd.Changes(), updating.NewChange(nil, "not a map", prop))
+
+	err = updating.ApplyBatch(yside, changes, false)
+	batchErr := &updating.BatchError{}
+	if !errors.As(err, &batchErr) {
+		log.Fail(t, "Expected a batch error")
+		return
+	}
+	if batchErr.PropertyId != "testproto.mystring2stringmap" || batchErr.Index != len(changes)-1 {
+		log.Fail(t, "Unexpected batch error ", batchErr.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(yside, original) {
+		log.Fail(t, "Expected the instance to be restored")
+		return
+	}
+
+	err = updating.ApplyBatch(yside, upd.Changes(), true)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(yside, zside) {
+		log.Fail(t, "Expected the batch to be applied")
+		return
+	}
+}
+
+func TestApplyBatchRollbackInPlace(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := utils.CreateTestModelInstance(1)
+	aside.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	aside.MySingle = &testtypes.TestProtoSub{MyString: "single"}
+	original := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	subs := aside.MyString2ModelMap
+	sub := aside.MyString2ModelMap["b"]
+	single := aside.MySingle
+
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 7}
+	zside.MyString2ModelMap["c"] = &testtypes.TestProtoSub{MyString: "c", MyInt64: 8}
+	zside.MySingle.MyString = "changed"
+	upd := updating.NewUpdater(res, false, true)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	prop, err := properties.PropertyOf("testproto.mystring2stringmap", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	changes = append(changes, updating.NewChange(nil, "not a map", prop))
+
+	err = updating.ApplyBatch(aside, changes, false)
+	if err == nil {
+		log.Fail(t, "Expected the batch to fail")
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected the instance to be restored")
+		return
+	}
+	if len(aside.MyString2ModelMap) != len(original.MyString2ModelMap) ||
+		aside.MyString2ModelMap["b"] != sub || aside.MySingle != single {
+		log.Fail(t, "Expected the inner pointers and maps to be restored in place")
+		return
+	}
+	aside.MyString2ModelMap["d"] = nil
+	if _, ok := subs["d"]; !ok {
+		log.Fail(t, "Expected the map to be the original map")
+		return
+	}
+}
+
+func TestApplyBatchRollbackKeyedSlice(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	aside := newKeyedModel()
+	original := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside.Items = []*KeyedItem{zside.Items[2], zside.Items[0], {Name: "new"}}
+	upd := updating.NewUpdater(res, true, true)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	prop, err := properties.PropertyOf("keyedmodel.items<{2}0>", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	changes = append(changes, updating.NewChange(nil, "not an item", prop))
+	err = updating.ApplyBatch(aside, changes, false)
+	if err == nil {
+		log.Fail(t, "Expected the batch to fail")
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected the deleted, inserted and moved elements to be restored")
+		return
+	}
+}
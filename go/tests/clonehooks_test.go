@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+type SecretCred struct {
+	User  string
+	Token string
+}
+
+// SecretModel is local, as TestProto has no slice or int32 fields.
+type SecretModel struct {
+	Name     string
+	Password string
+	Pin      int32
+	Notes    []string
+	Creds    map[string]*SecretCred
+}
+
+func newSecretModel() *SecretModel {
+	return &SecretModel{Name: "device", Password: "pass", Pin: 1234, Notes: []string{"a", "b"},
+		Creds: map[string]*SecretCred{"admin": {User: "admin", Token: "token"}}}
+}
+
+func TestCloneHooks(t *testing.T) {
+	cloner := cloning.NewCloner()
+	cloner.AddHook("secretmodel.password", &cloning.CloneHook{Action: cloning.HookMask})
+	cloner.AddHook("secretmodel.pin", &cloning.CloneHook{Action: cloning.HookMask})
+	cloner.AddHook("secretmodel.creds<*>", &cloning.CloneHook{Action: cloning.HookReplace, Replace: func(value interface{}) interface{} {
+		return &SecretCred{User: value.(*SecretCred).User}
+	}})
+	cloner.AddHook("secretmodel.notes<{2}1>", &cloning.CloneHook{Action: cloning.HookDrop})
+	model := newSecretModel()
+	clone := cloner.Clone(model).(*SecretModel)
+	if clone.Name != "device" || clone.Password != cloning.MaskedValue || clone.Pin != 0 {
+		log.Fail(t, "Expected the password and pin to be masked ", clone)
+		return
+	}
+	if clone.Creds["admin"].User != "admin" || clone.Creds["admin"].Token != "" {
+		log.Fail(t, "Expected the credentials to be replaced")
+		return
+	}
+	if len(clone.Notes) != 2 || clone.Notes[0] != "a" || clone.Notes[1] != "" {
+		log.Fail(t, "Expected the second note to be dropped ", clone.Notes)
+		return
+	}
+	if model.Password != "pass" || model.Creds["admin"].Token != "token" {
+		log.Fail(t, "Expected the original to be untouched")
+		return
+	}
+}
+
+func newSecretProto() *testtypes.TestProto {
+	model := utils.CreateTestModelInstance(1)
+	model.MyString = "pass"
+	model.MySingle = &testtypes.TestProtoSub{MyString: "name"}
+	model.MyString2ModelMap["admin"] = &testtypes.TestProtoSub{MyString: "token", MyInt64: 1}
+	return model
+}
+
+func TestSensitiveDecorator(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	root, _ := res.Introspector().Node("testproto")
+	password, _ := res.Introspector().Node("testproto.mystring")
+	token, _ := res.Introspector().Node("testproto.mystring2modelmap.mystring")
+	introspector := res.Introspector().(*introspecting.Introspector)
+	introspector.AddSensitiveDecorator(password)
+	introspector.AddSensitiveDecorator(token)
+
+	cloner := cloning.NewCloner()
+	cloner.AddSensitiveHooks(root, &cloning.CloneHook{Action: cloning.HookMask})
+	clone := cloner.Clone(newSecretProto()).(*testtypes.TestProto)
+	if clone.MyString != cloning.MaskedValue || clone.MyString2ModelMap["admin"].MyString != cloning.MaskedValue ||
+		clone.MyString2ModelMap["admin"].MyInt64 != 1 || clone.MySingle.MyString != "name" {
+		log.Fail(t, "Expected the sensitive fields to be masked")
+		return
+	}
+
+	old := newSecretProto()
+	new := newSecretProto()
+	new.MyString = "newpass"
+	new.MyString2ModelMap["admin"].MyString = "newtoken"
+	new.MyString2ModelMap["oper"] = &testtypes.TestProtoSub{MyString: "opertoken"}
+	changes, err := updating.NewUpdater(res, false, false).Diff(old, new)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	for _, change := range changes {
+		str, err := change.String()
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		if strings.Contains(str, "=pass") || strings.Contains(str, "newpass") || strings.Contains(str, "=token") ||
+			strings.Contains(str, "newtoken") || strings.Contains(str, "opertoken") {
+			log.Fail(t, "Expected the change log to be redacted: ", str)
+			return
+		}
+	}
+	if len(changes) != 3 {
+		log.Fail(t, "Expected 3 changes, got ", len(changes))
+		return
+	}
+}
+
+func TestCloneHookSegments(t *testing.T) {
+	cloner := cloning.NewCloner()
+	cloner.AddHook("*.myint64", &cloning.CloneHook{Action: cloning.HookMask})
+	clone := cloner.Clone(newSecretProto()).(*testtypes.TestProto)
+	if clone.MyString2ModelMap["admin"].MyInt64 != 1 {
+		log.Fail(t, "Expected a '*' not to span the dots of the property id")
+		return
+	}
+	cloner.AddHook("*.*.myint64", &cloning.CloneHook{Action: cloning.HookMask})
+	clone = cloner.Clone(newSecretProto()).(*testtypes.TestProto)
+	if clone.MyString2ModelMap["admin"].MyInt64 != 0 {
+		log.Fail(t, "Expected each '*' to match a segment of the property id")
+		return
+	}
+	if cloner.AddHook("testproto.[", &cloning.CloneHook{Action: cloning.HookMask}) == nil {
+		log.Fail(t, "Expected an error for an invalid pattern")
+		return
+	}
+}
+
+func TestSensitiveChangeAfterDecorator(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	password, _ := res.Introspector().Node("testproto.mystring")
+	name, _ := res.Introspector().Node("testproto.mysingle.mystring")
+	introspector := res.Introspector().(*introspecting.Introspector)
+	introspector.AddSensitiveDecorator(password)
+	old := newSecretProto()
+	new := newSecretProto()
+	new.MySingle.MyString = "newname"
+	changes, err := updating.NewUpdater(res, false, false).Diff(old, new)
+	if err != nil || len(changes) != 1 {
+		log.Fail(t, "Expected a single name change ", err)
+		return
+	}
+	str, _ := changes[0].String()
+	if !strings.Contains(str, "newname") {
+		log.Fail(t, "Expected the name not to be redacted: ", str)
+		return
+	}
+	introspector.AddSensitiveDecorator(name)
+	str, _ = changes[0].String()
+	if strings.Contains(str, "newname") {
+		log.Fail(t, "Expected the name to be redacted once it is sensitive: ", str)
+		return
+	}
+}
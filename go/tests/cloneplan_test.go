@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+)
+
+type PlanEnum int32
+
+type PlanFlat struct {
+	Id     int64
+	Name   string
+	Kind   PlanEnum
+	Values [16]int64
+}
+
+type PlanModel struct {
+	Flat   PlanFlat
+	Flats  []PlanFlat
+	Parent *PlanModel
+	Child  *PlanModel
+	hidden []int
+}
+
+func TestClonePlans(t *testing.T) {
+	model := &PlanModel{Flat: PlanFlat{Id: 1, Name: "flat", Kind: 2}, Flats: []PlanFlat{{Id: 2}}, hidden: []int{1}}
+	model.Child = &PlanModel{Parent: model}
+	cloner := cloning.NewCloner()
+	for i := 0; i < 2; i++ {
+		clone := cloner.Clone(model).(*PlanModel)
+		if clone.Flat != model.Flat || clone.Flats[0] != model.Flats[0] || clone.hidden != nil {
+			log.Fail(t, "Expected the pointer free fields to be copied and the skipped fields to be empty")
+			return
+		}
+		if &clone.Flats[0] == &model.Flats[0] {
+			log.Fail(t, "Expected the slice of pointer free structs to be copied")
+			return
+		}
+		if clone.Child == model.Child || clone.Child.Parent != clone {
+			log.Fail(t, "Expected the recursive type to be cloned with its cycle")
+			return
+		}
+	}
+	kind := cloner.Clone(PlanEnum(3))
+	if kind != PlanEnum(3) {
+		log.Fail(t, "Expected a named type to be cloned as itself, got ", kind)
+		return
+	}
+	//adding a shared type after the plans were built must rebuild them
+	cloner.AddSharedType(reflect.TypeOf(PlanModel{}))
+	clone := cloner.Clone(model).(*PlanModel)
+	if clone != model {
+		log.Fail(t, "Expected the shared type to be shared after its plan was built")
+		return
+	}
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func TestCopyIntoReusesAllocations(t *testing.T) {
+	cloner := cloning.NewCloner()
+	src := utils.CreateTestModelInstance(1)
+	src.MySingle = &testtypes.TestProtoSub{MyString: "single", MyInt64: 9}
+	src.MyString2ModelMap["a"] = &testtypes.TestProtoSub{MyString: "a", MyInt64: 8}
+	dst := utils.CreateTestModelInstance(2)
+	dst.MySingle = &testtypes.TestProtoSub{MyString: "single"}
+	dst.MyString2ModelMap["a"] = &testtypes.TestProtoSub{MyString: "a"}
+	dst.MyString2ModelMap["c"] = &testtypes.TestProtoSub{MyString: "c"}
+	single := dst.MySingle
+	subA := dst.MyString2ModelMap["a"]
+	subs := dst.MyString2ModelMap
+
+	err := cloner.CopyInto(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(dst, src) {
+		log.Fail(t, "Expected the copy to be equal to the source, ", cloning.NewDeepEqual().Explain(dst, src).String())
+		return
+	}
+	if dst.MySingle != single || dst.MyString2ModelMap["a"] != subA {
+		log.Fail(t, "Expected the existing pointers to be reused")
+		return
+	}
+	subs["d"] = nil
+	if _, ok := dst.MyString2ModelMap["d"]; !ok {
+		log.Fail(t, "Expected the existing map to be reused")
+		return
+	}
+	delete(subs, "d")
+	if dst.MySingle == src.MySingle || dst.MyString2ModelMap["a"] == src.MyString2ModelMap["a"] {
+		log.Fail(t, "Expected the copy not to share pointers with the source")
+		return
+	}
+	if _, ok := dst.MyString2ModelMap["c"]; ok {
+		log.Fail(t, "Expected map entries missing from the source to be removed")
+		return
+	}
+}
+
+func TestCopyIntoAllocations(t *testing.T) {
+	cloner := cloning.NewCloner()
+	src := utils.CreateTestModelInstance(1)
+	dst := &testtypes.TestProto{}
+	err := cloner.CopyInto(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	copyAllocs := testing.AllocsPerRun(100, func() { cloner.CopyInto(dst, src) })
+	cloneAllocs := testing.AllocsPerRun(100, func() { cloner.Clone(src) })
+	if copyAllocs >= cloneAllocs {
+		log.Fail(t, "Expected CopyInto to allocate less than Clone, got ", copyAllocs, " and ", cloneAllocs)
+		return
+	}
+
+	//the map entries are copied through a single temporary value, entries of the same pointer stay aliased
+	sub := &testtypes.TestProtoSub{MyString: "shared"}
+	src.MyString2ModelMap["x"] = sub
+	src.MyString2ModelMap["y"] = sub
+	err = cloner.CopyInto(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if dst.MyString2ModelMap["x"] != dst.MyString2ModelMap["y"] || dst.MyString2ModelMap["x"] == sub ||
+		dst.MyString2ModelMap["x"].MyString != "shared" {
+		log.Fail(t, "Expected the map entries of the same pointer to share a copy")
+		return
+	}
+}
+
+// TestCopyIntoReusesSlices uses RevertModel, as TestProto has no slice fields.
+func TestCopyIntoReusesSlices(t *testing.T) {
+	src := newRevertModel()
+	dst := newRevertModel()
+	dst.Items = make([]*RevertSub, 0, 10)
+	err := cloning.NewCloner().CopyInto(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(dst, src) || cap(dst.Items) != 10 {
+		log.Fail(t, "Expected the existing slice to be reused")
+		return
+	}
+	if dst.Items[0] == src.Items[0] {
+		log.Fail(t, "Expected the copy not to share pointers with the source")
+		return
+	}
+}
+
+// CopyNode is local, as TestProto has no cycles.
+type CopyNode struct {
+	Name string
+	Next *CopyNode
+}
+
+func TestCopyIntoCycle(t *testing.T) {
+	src := &CopyNode{Name: "a"}
+	src.Next = &CopyNode{Name: "b", Next: src}
+	dst := &CopyNode{}
+	err := cloning.NewCloner().CopyInto(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if dst.Next.Name != "b" || dst.Next.Next != dst {
+		log.Fail(t, "Expected the cycle to be preserved in the destination")
+		return
+	}
+	if cloning.NewCloner().CopyInto(&RevertSub{}, src) == nil {
+		log.Fail(t, "Expected an error copying into a different type")
+		return
+	}
+}
+
+// CopyAliasModel is local, as TestProto has no slice fields.
+type CopyAliasModel struct {
+	A []int32
+	B []int32
+	M map[string]int32
+	N map[string]int32
+}
+
+func TestCopyIntoAliasedDestination(t *testing.T) {
+	s := make([]int32, 2)
+	m := map[string]int32{}
+	dst := &CopyAliasModel{A: s, B: s, M: m, N: m}
+	src := &CopyAliasModel{A: []int32{1, 2}, B: []int32{3, 4}, M: map[string]int32{"x": 1}, N: map[string]int32{"y": 2}}
+	err := cloning.NewCloner().CopyInto(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(dst, src) {
+		log.Fail(t, "Expected the aliased slices and maps to be copied apart, ", cloning.NewDeepEqual().Explain(dst, src).String())
+		return
+	}
+	if &dst.A[0] == &dst.B[0] || len(dst.M) != 1 || len(dst.N) != 1 {
+		log.Fail(t, "Expected a slice and a map of the destination to be reused only once")
+		return
+	}
+}
+
+func TestCopyIntoHooks(t *testing.T) {
+	cloner := cloning.NewCloner()
+	cloner.AddHook("secretmodel.password", &cloning.CloneHook{Action: cloning.HookMask})
+	cloner.AddHook("secretmodel.creds<*>.token", &cloning.CloneHook{Action: cloning.HookMask})
+	cloner.AddHook("secretmodel.notes<{2}1>", &cloning.CloneHook{Action: cloning.HookDrop})
+	dst := &SecretModel{}
+	err := cloner.CopyInto(dst, newSecretModel())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if dst.Name != "device" || dst.Password != cloning.MaskedValue || dst.Creds["admin"].Token != cloning.MaskedValue {
+		log.Fail(t, "Expected the hooks to mask the copied fields ", dst)
+		return
+	}
+	if len(dst.Notes) != 2 || dst.Notes[0] != "a" || dst.Notes[1] != "" {
+		log.Fail(t, "Expected the second note to be dropped ", dst.Notes)
+		return
+	}
+}
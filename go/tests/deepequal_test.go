@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+)
+
+func TestDeepEqualAllKinds(t *testing.T) {
+	deq := cloning.NewDeepEqual()
+	ch := make(chan int)
+	aside := &KindsModel{Small: 1, Short: 2, Byte: 3, Word: 4, Cmplx: complex(1, 2), Data: []byte("data"),
+		Fixed: [3]int32{1, 2, 3}, Any: ch}
+	zside := &KindsModel{Small: 1, Short: 2, Byte: 3, Word: 4, Cmplx: complex(1, 2), Data: []byte("data"),
+		Fixed: [3]int32{1, 2, 3}, Any: ch}
+	if !deq.Equal(aside, zside) {
+		log.Fail(t, "Expected instances to be equal, ", deq.Explain(aside, zside).String())
+		return
+	}
+	zside.Any = make(chan int)
+	if deq.Equal(aside, zside) {
+		log.Fail(t, "Expected different channels not to be equal")
+		return
+	}
+	if deq.Equal(uintptr(1), uintptr(2)) || !deq.Equal(uintptr(1), uintptr(1)) {
+		log.Fail(t, "Expected uintptr to be compared by value")
+		return
+	}
+}
+
+func TestDeepEqualExplain(t *testing.T) {
+	deq := cloning.NewDeepEqual()
+	aside := newRevertModel()
+	zside := newRevertModel()
+	if deq.Explain(aside, zside) != nil {
+		log.Fail(t, "Expected no difference for equal instances")
+		return
+	}
+
+	zside.Subs["b"].Value = 7
+	diff := deq.Explain(aside, zside)
+	if diff == nil || diff.Path != "revertmodel.subs<{24}b>.value" || diff.ASide != int32(2) || diff.ZSide != int32(7) {
+		log.Fail(t, "Unexpected difference ", diff)
+		return
+	}
+
+	zside.Count = 3
+	zside.Names = append(zside.Names, "n3")
+	diffs := deq.ExplainAll(aside, zside)
+	expected := map[string]bool{
+		"revertmodel.count":             true,
+		"revertmodel.subs<{24}b>.value": true,
+		"revertmodel.names<{2}3>":       true,
+	}
+	if len(diffs) != len(expected) {
+		log.Fail(t, "Expected ", len(expected), " differences but got ", len(diffs))
+		return
+	}
+	for _, d := range diffs {
+		if !expected[d.Path] {
+			log.Fail(t, "Unexpected difference ", d.String())
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func newDiffModels() (*testtypes.TestProto, *testtypes.TestProto) {
+	aside := utils.CreateTestModelInstance(1)
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyString = "diff"
+	zside.MyString2StringMap["diff"] = "2"
+	zside.MySingle.MyString = "diff"
+	return aside, zside
+}
+
+func TestDiffDoesNotModify(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside, zside := newDiffModels()
+	asideCopy := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zsideCopy := cloning.NewCloner().Clone(zside).(*testtypes.TestProto)
+
+	upd := updating.NewUpdater(res, false, false)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 3 {
+		log.Fail(t, "Expected 3 changes but got ", len(changes))
+		return
+	}
+	if len(upd.Changes()) != 0 {
+		log.Fail(t, "Expected diff not to record changes on the updater")
+		return
+	}
+	deq := cloning.NewDeepEqual()
+	if !deq.Equal(aside, asideCopy) || !deq.Equal(zside, zsideCopy) {
+		log.Fail(t, "Expected diff to leave both inputs untouched")
+		return
+	}
+
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(upd.Changes()) != len(changes) {
+		log.Fail(t, "Expected diff and update to report the same changes")
+		return
+	}
+	ids := make(map[string]bool)
+	for _, change := range changes {
+		ids[change.PropertyId()] = true
+	}
+	for _, change := range upd.Changes() {
+		if !ids[change.PropertyId()] {
+			log.Fail(t, "Unexpected change ", change.PropertyId())
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func newEqualModels() (*testtypes.TestProto, *testtypes.TestProto) {
+	aside := utils.CreateTestModelInstance(1)
+	aside.MyEnum = testtypes.TestEnum_ValueOne
+	aside.MyFloat64 = 0.5
+	aside.MyString2StringMap = nil
+	aside.MyString2ModelMap["a"] = &testtypes.TestProtoSub{MyString: "a", MyInt64: 1}
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyEnum = testtypes.TestEnum_ValueTwo
+	zside.MyFloat64 = 0.5000001
+	zside.MyString2StringMap = map[string]string{}
+	zside.MyString2ModelMap["a"].MyInt64 = 2
+	return aside, zside
+}
+
+func TestDeepEqualOptions(t *testing.T) {
+	aside, zside := newEqualModels()
+	deq := cloning.NewDeepEqual()
+	if deq.Equal(aside, zside) {
+		log.Fail(t, "Expected a strict comparison to find differences")
+		return
+	}
+	options := &cloning.EqualOptions{NilIsEmpty: true, FloatEpsilon: 0.001}
+	options.IgnoreId("testproto.myenum", "testproto.mystring2modelmap.myint64")
+	deq.SetOptions(options)
+	if !deq.Equal(aside, zside) {
+		log.Fail(t, "Expected the options to ignore the differences, ", deq.Explain(aside, zside).String())
+		return
+	}
+}
+
+// EqualModel is local, as TestProto has no slice fields.
+type EqualModel struct {
+	Tags []string
+}
+
+func TestDeepEqualUnorderedSlices(t *testing.T) {
+	aside := &EqualModel{Tags: []string{"a", "b", "c"}}
+	zside := &EqualModel{Tags: []string{"c", "a", "b"}}
+	deq := cloning.NewDeepEqual()
+	if deq.Equal(aside, zside) {
+		log.Fail(t, "Expected a strict comparison to find differences")
+		return
+	}
+	deq.SetOptions(&cloning.EqualOptions{UnorderedSlices: true})
+	if !deq.Equal(aside, zside) {
+		log.Fail(t, "Expected unordered slices to ignore the order")
+		return
+	}
+	zside.Tags = []string{"c", "a", "a"}
+	if deq.Equal(aside, zside) {
+		log.Fail(t, "Expected unordered slices to be compared as multisets")
+		return
+	}
+}
+
+func TestUpdaterEqualOptions(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside, zside := newEqualModels()
+	zside.MyString = "other"
+
+	options := &cloning.EqualOptions{NilIsEmpty: true, FloatEpsilon: 0.001}
+	options.IgnoreId("testproto.myenum", "testproto.mystring2modelmap<{24}a>.myint64")
+	upd := updating.NewUpdater(res, true, true)
+	upd.SetEqualOptions(options)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 1 || changes[0].PropertyId() != "testproto.mystring" {
+		for _, change := range changes {
+			log.Info(change.PropertyId())
+		}
+		log.Fail(t, "Expected only the string to change but got ", len(changes), " changes")
+		return
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func newGenericModel() *testtypes.TestProto {
+	model := utils.CreateTestModelInstance(1)
+	model.MyString = "model"
+	model.MyString2ModelMap["a"] = &testtypes.TestProtoSub{MyString: "a", MyInt64: 1}
+	return model
+}
+
+func TestGenericHelpers(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	model := newGenericModel()
+	clone, err := cloning.Clone(cloning.NewCloner(), model)
+	if err != nil || clone == model || clone.MyString2ModelMap["a"].MyString != "a" {
+		log.Fail(t, "Expected a typed clone ", err)
+		return
+	}
+
+	prop, err := properties.PropertyOf("testproto.mystring", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	names, err := properties.GetAs[string](prop, model)
+	if err != nil || len(names) != 1 || names[0] != "model" {
+		log.Fail(t, "Expected the name as a string ", names, err)
+		return
+	}
+	_, err = properties.GetAs[int](prop, model)
+	if err == nil {
+		log.Fail(t, "Expected an error getting a string as an int")
+		return
+	}
+	_, err = properties.GetAs[string](prop, nil)
+	if err == nil {
+		log.Fail(t, "Expected an error getting from a nil instance")
+		return
+	}
+	_, err = properties.GetAs[string](prop, &testtypes.TestProtoSub{})
+	if err == nil {
+		log.Fail(t, "Expected an error getting from an instance of another type")
+		return
+	}
+	err = properties.SetAs(prop, model, "other")
+	if err != nil || model.MyString != "other" {
+		log.Fail(t, "Expected the name to be set ", err)
+		return
+	}
+	err = properties.SetAs(prop, model, 5)
+	if err == nil {
+		log.Fail(t, "Expected an error setting an int to a string")
+		return
+	}
+
+	subs, err := properties.PropertyOf("testproto.mystring2modelmap<{24}a>", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	values, err := properties.GetAs[*testtypes.TestProtoSub](subs, model)
+	if err != nil || len(values) != 1 || values[0].MyString != "a" {
+		log.Fail(t, "Expected the map entry as *TestProtoSub ", err)
+		return
+	}
+
+	updated := newGenericModel()
+	updated.MyString2ModelMap["a"].MyInt64 = 5
+	updater := updating.NewUpdater(res, false, false)
+	err = updating.UpdateAs(updater, newGenericModel(), updated)
+	if err != nil || len(updater.Changes()) != 1 {
+		log.Fail(t, "Expected a single change ", err)
+		return
+	}
+	oldValue, newValue, err := updating.ValuesAs[int64](updater.Changes()[0])
+	if err != nil || oldValue != 1 || newValue != 5 {
+		log.Fail(t, "Expected the change values as int64 ", err)
+		return
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/hashing"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+// HashModel is local, as TestProto has no unexported fields.
+type HashModel struct {
+	Name    string
+	Entries map[string]int32
+	hidden  string
+}
+
+func TestHashIsStructural(t *testing.T) {
+	hasher := hashing.NewHasher(nil)
+	aside := &HashModel{Name: "model", Entries: map[string]int32{}, hidden: "a"}
+	zside := &HashModel{Name: "model", Entries: map[string]int32{}, hidden: "z"}
+	for i := 0; i < 100; i++ {
+		aside.Entries[string(rune('a'+i%26))+string(rune('a'+i/26))] = int32(i)
+	}
+	for i := 99; i >= 0; i-- {
+		zside.Entries[string(rune('a'+i%26))+string(rune('a'+i/26))] = int32(i)
+	}
+	if hasher.Hash(aside) != hasher.Hash(zside) {
+		log.Fail(t, "Expected the hash to ignore the map order and unexported fields")
+		return
+	}
+	zside.Entries["aa"] = 7
+	if hasher.Hash(aside) == hasher.Hash(zside) {
+		log.Fail(t, "Expected a different hash for a different value")
+		return
+	}
+}
+
+func TestHashTree(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	hasher := hashing.NewHasher(res)
+	aside := newHashModel()
+	zside := newHashModel()
+	zside.MyString2ModelMap["b"].MyInt64 = 7
+
+	aTree := hasher.HashTree(aside)
+	zTree := hasher.HashTree(zside)
+	if aTree.Hash != hasher.Hash(aside) {
+		log.Fail(t, "Expected the root of the tree to be the instance hash")
+		return
+	}
+	if aTree.Find("testproto.mystring2modelmap<{24}a>").Hash != zTree.Find("testproto.mystring2modelmap<{24}a>").Hash {
+		log.Fail(t, "Expected an unchanged subtree to have the same hash")
+		return
+	}
+	diff := aTree.Diff(zTree)
+	if len(diff) != 1 || diff[0] != "testproto.mystring2modelmap<{24}b>" {
+		log.Fail(t, "Expected the diff to be the changed map entry but got ", diff)
+		return
+	}
+
+	upd := updating.NewUpdater(res, true, true)
+	upd.SetHashTrees(aTree, zTree)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 1 || changes[0].PropertyId() != "testproto.mystring2modelmap<{24}b>.myint64" {
+		log.Fail(t, "Expected a single change with the hash trees")
+		return
+	}
+
+	//subtrees with the same hash are not compared at all
+	upd.SetHashTrees(aTree, aTree)
+	changes, err = upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 0 {
+		log.Fail(t, "Expected the equal root hashes to skip the update")
+		return
+	}
+}
+
+func newHashModel() *testtypes.TestProto {
+	model := utils.CreateTestModelInstance(1)
+	model.MyString2ModelMap["a"] = &testtypes.TestProtoSub{MyString: "a", MyInt64: 1}
+	model.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	return model
+}
+
+// HashCycle is local, as TestProto has no cycles.
+type HashCycle struct {
+	Name  string
+	Next  *HashCycle
+	Links map[string]*HashCycle
+}
+
+func TestHashCycles(t *testing.T) {
+	hasher := hashing.NewHasher(nil)
+	aside := &HashCycle{Name: "a", Links: map[string]*HashCycle{}}
+	aside.Next = aside
+	aside.Links["self"] = aside
+	zside := &HashCycle{Name: "a", Links: map[string]*HashCycle{}}
+	zside.Next = zside
+	zside.Links["self"] = zside
+	if hasher.Hash(aside) != hasher.Hash(zside) {
+		log.Fail(t, "Expected the same cycles to have the same hash")
+		return
+	}
+	zside.Name = "z"
+	if hasher.Hash(aside) == hasher.Hash(zside) {
+		log.Fail(t, "Expected a different hash for a different value")
+		return
+	}
+}
+
+func TestHashTreeKeyedSlice(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	hasher := hashing.NewHasher(res)
+	aside := newKeyedModel()
+	zside := newKeyedModel()
+	//the elements are moved and one of them is modified
+	zside.Items = []*KeyedItem{zside.Items[2], zside.Items[0], zside.Items[1]}
+	zside.Items[0].Value = 7
+
+	aTree := hasher.HashTree(aside)
+	zTree := hasher.HashTree(zside)
+	if aTree.Find("keyedmodel.items<{24}i1>") == nil ||
+		aTree.Find("keyedmodel.items<{24}i1>").Hash != zTree.Find("keyedmodel.items<{24}i1>").Hash {
+		log.Fail(t, "Expected the keyed elements to be found by their primary key")
+		return
+	}
+	upd := updating.NewUpdater(res, true, true)
+	upd.SetHashTrees(aTree, zTree)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	for _, change := range changes {
+		if change.PropertyId() == "keyedmodel.items<{24}i2>.value" {
+			return
+		}
+	}
+	log.Fail(t, "Expected the change of the moved element")
+}
+
+type HashScalars struct {
+	Name   string
+	Count  int64
+	Ratio  float64
+	Active bool
+	Label  string
+}
+
+type HashScalar struct {
+	Name string
+}
+
+func TestHashScalarAllocations(t *testing.T) {
+	hasher := hashing.NewHasher(nil)
+	one := &HashScalar{Name: "one"}
+	many := &HashScalars{Name: "many", Count: 1, Ratio: 0.5, Active: true, Label: "label"}
+	oneAllocs := testing.AllocsPerRun(100, func() { hasher.Hash(one) })
+	manyAllocs := testing.AllocsPerRun(100, func() { hasher.Hash(many) })
+	if manyAllocs != oneAllocs {
+		log.Fail(t, "Expected hashing scalar fields not to allocate, got ", manyAllocs, " and ", oneAllocs)
+		return
+	}
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func TestJsonPatchRoundTrip(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := utils.CreateTestModelInstance(1)
+	aside.MyString2StringMap["a"] = "1"
+	aside.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	yside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyString = "patched"
+	delete(zside.MyString2StringMap, "a")
+	zside.MyString2StringMap["c/d"] = "4"
+	zside.MyString2ModelMap["b"].MyInt64 = 7
+	zside.MyString2ModelMap["c"] = &testtypes.TestProtoSub{MyString: "c"}
+	zside.MySingle = nil
+
+	//the pointers use the json names of the fields, so they are compared lowercased
+	if !jsonPatchRoundTrip(t, res, aside, yside, zside, map[string]string{
+		"/mystring":                    "replace",
+		"/mystring2stringmap/a":        "remove",
+		"/mystring2stringmap/c~1d":     "add",
+		"/mystring2modelmap/b/myint64": "replace",
+		"/mystring2modelmap/c":         "add",
+		"/mysingle":                    "remove",
+	}) {
+		return
+	}
+}
+
+// TestJsonPatchSlices uses RevertModel, as TestProto has no slice fields.
+func TestJsonPatchSlices(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newRevertModel()
+	yside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside.Items = zside.Items[:1]
+	zside.Names = append(zside.Names, "n3")
+	if !jsonPatchRoundTrip(t, res, aside, yside, zside, map[string]string{
+		"/items/1": "remove",
+		"/items/2": "remove",
+		"/names/3": "add",
+	}) {
+		return
+	}
+}
+
+// jsonPatchRoundTrip updates aside to zside, expecting the operations of the paths in the patch,
+// and expects the patch to update yside to zside.
+func jsonPatchRoundTrip(t *testing.T, res ifs.IResources, aside, yside, zside interface{}, expected map[string]string) bool {
+	upd := updating.NewUpdater(res, true, true)
+	err := upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	patch, err := updating.ToJsonPatch(upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	operations := make([]map[string]interface{}, 0)
+	err = json.Unmarshal(patch, &operations)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	paths := make(map[string]string)
+	for _, operation := range operations {
+		paths[strings.ToLower(operation["path"].(string))] = operation["op"].(string)
+	}
+	for path, op := range expected {
+		if paths[path] != op {
+			log.Fail(t, "Expected ", op, " for ", path, " in ", string(patch))
+			return false
+		}
+	}
+	_, err = updating.ApplyJsonPatch(yside, patch, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	if !cloning.NewDeepEqual().Equal(yside, zside) {
+		log.Fail(t, "Expected the patched instance to equal the updated one")
+		return false
+	}
+	return true
+}
+
+// TestJsonPatchParse uses RevertModel, as TestProto has no slice fields for the - index.
+func TestJsonPatchParse(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	patch := []byte(`[{"op":"replace","path":"/subs/a/value","value":9},{"op":"add","path":"/items/-","value":{"Name":"x"}}]`)
+	_, err = updating.ParseJsonPatch("RevertModel", patch, res)
+	if err == nil {
+		log.Fail(t, "Expected an error for the - index without an instance")
+		return
+	}
+
+	aside := newRevertModel()
+	changes, err := updating.ApplyJsonPatch(aside, patch, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 2 || changes[0].PropertyId() != "revertmodel.subs<{24}a>.value" {
+		log.Fail(t, "Unexpected changes")
+		return
+	}
+	if aside.Subs["a"].Value != 9 || len(aside.Items) != 4 || aside.Items[3].Name != "x" {
+		log.Fail(t, "Expected the patch to be applied")
+		return
+	}
+}
+
+func TestJsonPatchKeyedSlice(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	aside := newKeyedModel()
+	yside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside.Items = []*KeyedItem{zside.Items[2], zside.Items[0], zside.Items[1]}
+	zside.Items[1].Value = 5
+
+	upd := updating.NewUpdater(res, true, true)
+	err := upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	operations, err := updating.JsonPatchOperations(upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	moves := 0
+	for _, operation := range operations {
+		switch operation.Op {
+		case updating.JsonPatchMove:
+			moves++
+			if operation.From != "/items/2" || operation.Path != "/items/0" {
+				log.Fail(t, "Unexpected move from ", operation.From, " to ", operation.Path)
+				return
+			}
+		case updating.JsonPatchReplace:
+			if operation.Path != "/items/0/value" {
+				log.Fail(t, "Expected the element to be pointed to by its index but got ", operation.Path)
+				return
+			}
+		default:
+			log.Fail(t, "Unexpected operation ", operation.Op, " ", operation.Path)
+			return
+		}
+	}
+	if moves != 1 {
+		log.Fail(t, "Expected a single move but got ", moves)
+		return
+	}
+
+	patch, err := updating.ToJsonPatch(upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	_, err = updating.ApplyJsonPatch(yside, patch, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(yside, zside) {
+		log.Fail(t, "Expected the patched instance to equal the updated one")
+		return
+	}
+}
+
+func TestJsonPatchSliceEditScriptRoundTrip(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newRevertModel()
+	yside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside.Names = []string{"n0", "x", "n2", "y"}
+	zside.Items = []*RevertSub{{Name: "new"}, zside.Items[0], zside.Items[2]}
+
+	upd := updating.NewUpdater(res, true, true)
+	upd.SetSliceEditScript(true)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	patch, err := updating.ToJsonPatch(upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	_, err = updating.ApplyJsonPatch(yside, patch, res)
+	if err != nil {
+		log.Fail(t, err.Error(), " ", string(patch))
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(yside, zside) {
+		log.Fail(t, "Expected the patched instance to equal the updated one ", string(patch))
+		return
+	}
+
+	//a mid slice remove and add, without an instance
+	patch = []byte(`[{"op":"remove","path":"/names/1"},{"op":"add","path":"/names/1","value":"x"}]`)
+	changes, err := updating.ParseJsonPatch("RevertModel", patch, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	names := newRevertModel()
+	for _, change := range changes {
+		err = change.Apply(names)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+	}
+	if len(names.Names) != 3 || names.Names[1] != "x" || names.Names[2] != "n2" {
+		log.Fail(t, "Unexpected names ", names.Names)
+		return
+	}
+}
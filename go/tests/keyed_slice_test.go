@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+)
+
+type KeyedItem struct {
+	Name  string
+	Value int32
+}
+
+type KeyedModel struct {
+	Name  string
+	Items []*KeyedItem
+}
+
+func newKeyedResources(t *testing.T) ifs.IResources {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&KeyedModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return nil
+	}
+	node, ok := res.Introspector().Node("keyedmodel.items")
+	if !ok {
+		log.Fail(t, "Expected a node for keyedmodel.items")
+		return nil
+	}
+	introspecting.AddPrimaryKeyDecorator(node, "Name")
+	return res
+}
+
+func newKeyedModel() *KeyedModel {
+	return &KeyedModel{Name: "model", Items: []*KeyedItem{{Name: "i0"}, {Name: "i1", Value: 1}, {Name: "i2", Value: 2}}}
+}
+
+func TestKeyedSliceInsertAtFront(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	aside := newKeyedModel()
+	original := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside.Items = append([]*KeyedItem{{Name: "new", Value: 9}}, zside.Items...)
+
+	upd := updating.NewUpdater(res, true, true)
+	err := upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	inserts := 0
+	for _, change := range upd.Changes() {
+		if change.OldValue() == nil {
+			inserts++
+			if change.PropertyId() != "keyedmodel.items<{24}new>" {
+				log.Fail(t, "Unexpected insert ", change.PropertyId())
+				return
+			}
+			continue
+		}
+		if _, ok := change.NewValue().(properties.MoveTo); !ok {
+			log.Fail(t, "Expected only an insert and moves but got ", change.PropertyId())
+			return
+		}
+	}
+	if inserts != 1 {
+		log.Fail(t, "Expected a single insert but got ", inserts)
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, zside) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+
+	err = updating.Revert(aside, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
+
+func TestKeyedSliceRotateMovesOnce(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	aside := newKeyedModel()
+	aside.Items = append(aside.Items, &KeyedItem{Name: "i3", Value: 3})
+	original := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside.Items = append(zside.Items[1:], zside.Items[0])
+
+	upd := updating.NewUpdater(res, true, true)
+	err := upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	changes := upd.Changes()
+	if len(changes) != 1 || changes[0].PropertyId() != "keyedmodel.items<{24}i0>" ||
+		changes[0].NewValue() != properties.MoveTo(3) {
+		log.Fail(t, "Expected a single move of i0 but got ", len(changes), " changes")
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, zside) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+	err = updating.Revert(aside, changes)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
+
+func TestKeyedSliceDeleteAndModify(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	aside := newKeyedModel()
+	original := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside.Items = []*KeyedItem{zside.Items[0], zside.Items[2]}
+	zside.Items[1].Value = 7
+
+	upd := updating.NewUpdater(res, true, true)
+	err := upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	changes := upd.Changes()
+	if len(changes) != 2 {
+		log.Fail(t, "Expected 2 changes but got ", len(changes))
+		return
+	}
+	if changes[0].PropertyId() != "keyedmodel.items<{24}i2>.value" {
+		log.Fail(t, "Expected a modify by key but got ", changes[0].PropertyId())
+		return
+	}
+	if changes[1].PropertyId() != "keyedmodel.items<{24}i1>" || changes[1].NewValue() != ifs.Deleted_Entry {
+		log.Fail(t, "Expected a delete by key but got ", changes[1].PropertyId())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, zside) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+
+	err = updating.Revert(aside, changes)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
+
+func TestKeyedSliceApplyByKey(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	aside := newKeyedModel()
+	zside := cloning.NewCloner().Clone(aside).(*KeyedModel)
+	zside.Items = []*KeyedItem{zside.Items[2], zside.Items[0], zside.Items[1]}
+	zside.Items[0].Value = 5
+
+	changes, err := updating.NewUpdater(res, true, true).Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	target := newKeyedModel()
+	err = updating.ApplyBatch(target, changes, true)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(target, zside) {
+		log.Fail(t, "Expected the applied changes to reorder the items by key")
+		return
+	}
+}
+
+func TestKeyedSliceNotUniqueFallsBackToIndex(t *testing.T) {
+	res := newKeyedResources(t)
+	if res == nil {
+		return
+	}
+	for _, items := range [][]*KeyedItem{
+		{{Name: "i0"}, {Name: "i0", Value: 5}, {Name: "i2", Value: 2}},
+		{{Name: "i0"}, nil, {Name: "i2", Value: 2}},
+	} {
+		aside := newKeyedModel()
+		zside := &KeyedModel{Name: "model", Items: items}
+		upd := updating.NewUpdater(res, true, true)
+		err := upd.Update(aside, zside)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		if !cloning.NewDeepEqual().Equal(aside, zside) {
+			log.Fail(t, "Expected no element to be lost")
+			return
+		}
+		for _, change := range upd.Changes() {
+			if change.PropertyId() != "keyedmodel.items<{2}1>" && change.PropertyId() != "keyedmodel.items<{2}1>.value" &&
+				change.PropertyId() != "keyedmodel.items<{2}1>.name" {
+				log.Fail(t, "Expected the slice to be diffed by index but got ", change.PropertyId())
+				return
+			}
+		}
+	}
+}
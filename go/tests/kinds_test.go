@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+)
+
+type KindsModel struct {
+	Name  string
+	Small int8
+	Short int16
+	Byte  uint8
+	Word  uint16
+	Cmplx complex128
+	Data  []byte
+	Fixed [3]int32
+	Any   interface{}
+}
+
+func newKindsModel() *KindsModel {
+	return &KindsModel{Name: "kinds", Small: 1, Short: 2, Byte: 3, Word: 4, Cmplx: complex(1, 2),
+		Data: []byte("data"), Fixed: [3]int32{1, 2, 3}, Any: "any"}
+}
+
+func TestUpdateAllKinds(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&KindsModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newKindsModel()
+	original := newKindsModel()
+	zside := &KindsModel{Name: "kinds", Small: -1, Short: -2, Byte: 30, Word: 40, Cmplx: complex(2, 1),
+		Data: []byte("other data"), Fixed: [3]int32{3, 2, 1}, Any: int32(5)}
+
+	upd := updating.NewUpdater(res, true, true)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(upd.Changes()) != 8 {
+		log.Fail(t, "Expected 8 changes but got ", len(upd.Changes()))
+		return
+	}
+	for _, change := range upd.Changes() {
+		if change.PropertyId() == "kindsmodel.data" && string(change.NewValue().([]byte)) != "other data" {
+			log.Fail(t, "Expected a []byte to be a single leaf change")
+			return
+		}
+	}
+	deq := cloning.NewDeepEqual()
+	if !deq.Equal(aside, zside) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+
+	err = updating.Revert(aside, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !deq.Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
+
+func TestUpdateInterfaceSameType(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&KindsModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newKindsModel()
+	zside := newKindsModel()
+	changes, err := updating.NewUpdater(res, true, true).Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 0 {
+		log.Fail(t, "Expected no changes for equal instances but got ", len(changes))
+		return
+	}
+	zside.Any = "other"
+	changes, err = updating.NewUpdater(res, true, true).Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(changes) != 1 || changes[0].PropertyId() != "kindsmodel.any" || changes[0].NewValue() != "other" {
+		log.Fail(t, "Expected a single change of the interface value")
+		return
+	}
+}
+
+type KindsSub struct {
+	Name  string
+	Count int32
+}
+
+func TestUpdateInterfaceField(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&KindsModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	_, err = res.Introspector().Inspect(&KindsSub{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newKindsModel()
+	sub := &KindsSub{Name: "sub", Count: 1}
+	aside.Any = sub
+	yside := newKindsModel()
+	yside.Any = &KindsSub{Name: "sub", Count: 1}
+	zside := newKindsModel()
+	zside.Any = &KindsSub{Name: "sub", Count: 2}
+
+	upd := updating.NewUpdater(res, true, true)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(upd.Changes()) != 1 || upd.Changes()[0].PropertyId() != "kindsmodel.any.count" {
+		log.Fail(t, "Expected a single change of the field behind the interface")
+		return
+	}
+	if aside.Any != sub || sub.Count != 2 {
+		log.Fail(t, "Expected the struct behind the interface to be modified in place")
+		return
+	}
+	err = upd.Changes()[0].Apply(yside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if yside.Any.(*KindsSub).Count != 2 {
+		log.Fail(t, "Expected the change to be applied behind the interface")
+		return
+	}
+}
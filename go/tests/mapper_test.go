@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/mapping"
+)
+
+type MapperStatus int32
+
+type MapperSource struct {
+	Id       string
+	Name     string
+	Count    int32
+	Status   MapperStatus
+	Ratio    float32
+	Owner    *MapperSourceOwner
+	Owners   []*MapperSourceOwner
+	ByName   map[string]*MapperSourceOwner
+	Tags     []string
+	Internal string
+}
+
+type MapperSourceOwner struct {
+	Name  string
+	Level int32
+}
+
+type MapperDto struct {
+	ID       string
+	FullName string
+	Count    int64
+	Status   int32
+	Ratio    float64
+	Owner    MapperDtoOwner
+	Owners   []MapperDtoOwner
+	ByName   map[string]*MapperDtoOwner
+	Tags     []string
+	Label    string `map:"Internal"`
+	Extra    string
+}
+
+type MapperDtoOwner struct {
+	Name  string
+	Level uint8
+}
+
+func TestMapper(t *testing.T) {
+	src := &MapperSource{Id: "1", Name: "name", Count: 5, Status: 2, Ratio: 0.5,
+		Owner:  &MapperSourceOwner{Name: "owner", Level: 3},
+		Owners: []*MapperSourceOwner{{Name: "a", Level: 1}},
+		ByName: map[string]*MapperSourceOwner{"b": {Name: "b", Level: 2}},
+		Tags:   []string{"x"}, Internal: "internal"}
+	mapper := mapping.NewMapper(nil)
+	mapper.Rename("MapperSource", "Name", "FullName")
+	mapper.SetTag("map")
+	dst := &MapperDto{}
+	unmapped, err := mapper.Map(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if dst.ID != "1" || dst.FullName != "name" || dst.Count != 5 || dst.Status != 2 || dst.Ratio != 0.5 || dst.Label != "internal" {
+		log.Fail(t, "Expected the fields to be mapped by name, rename and tag ", dst)
+		return
+	}
+	if dst.Owner.Name != "owner" || dst.Owner.Level != 3 || dst.Owners[0].Name != "a" || dst.ByName["b"].Level != 2 {
+		log.Fail(t, "Expected the nested structs, slices and maps to be mapped")
+		return
+	}
+	src.Tags[0] = "y"
+	if dst.Tags[0] != "x" {
+		log.Fail(t, "Expected the values of the same type to be cloned")
+		return
+	}
+	if len(unmapped) != 1 || unmapped[0].PropertyId != "mappersource.extra" {
+		log.Fail(t, "Expected only the extra destination field to be unmapped ", unmapped)
+		return
+	}
+
+	back := &MapperSource{}
+	unmapped, err = mapping.NewMapper(nil).Map(back, &struct {
+		Name  string
+		Count string
+	}{Name: "n", Count: "x"})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if back.Name != "n" || len(unmapped) == 0 || unmapped[0].Reason != "cannot convert string to int32" {
+		log.Fail(t, "Expected the string to int32 field to be reported as unmapped ", unmapped)
+		return
+	}
+}
+
+type MapperNamedSource struct {
+	Id       string
+	State    int32       `json:"admin_status"`
+	PortMtu  int32       `protobuf:"varint,3,opt,name=mtu_size,json=mtuSize,proto3" json:"mtu_size,omitempty"`
+	Owner    interface{} `json:"owner"`
+	Contacts interface{} `json:"contacts"`
+}
+
+type MapperNamedDto struct {
+	Id          string
+	AdminStatus int64 `protobuf:"varint,2,opt,name=admin_status,json=adminStatus,proto3" json:"admin_status,omitempty"`
+	MtuSize     int64
+	Owner       *MapperDtoOwner
+	Contacts    []MapperDtoOwner
+}
+
+func TestMapperInterfacesAndNames(t *testing.T) {
+	src := &MapperNamedSource{Id: "1", State: 1, PortMtu: 1500,
+		Owner:    &MapperSourceOwner{Name: "owner", Level: 3},
+		Contacts: []*MapperSourceOwner{{Name: "a", Level: 1}}}
+	dst := &MapperNamedDto{}
+	unmapped, err := mapping.NewMapper(newResources()).Map(dst, src)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if dst.AdminStatus != 1 || dst.MtuSize != 1500 {
+		log.Fail(t, "Expected the fields to be mapped by their json and protobuf names ", dst)
+		return
+	}
+	if dst.Owner == nil || dst.Owner.Name != "owner" || dst.Owner.Level != 3 ||
+		len(dst.Contacts) != 1 || dst.Contacts[0].Name != "a" {
+		log.Fail(t, "Expected the structs and slices held by interfaces to be mapped")
+		return
+	}
+	if len(unmapped) != 0 {
+		log.Fail(t, "Expected all the fields to be mapped ", unmapped)
+		return
+	}
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func newMergeModels() (*testtypes.TestProto, *testtypes.TestProto, *testtypes.TestProto) {
+	base := utils.CreateTestModelInstance(1)
+	base.MyString2StringMap["a"] = "1"
+	base.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	base.MySingle = &testtypes.TestProtoSub{MyString: "single"}
+	ours := cloning.NewCloner().Clone(base).(*testtypes.TestProto)
+	theirs := cloning.NewCloner().Clone(base).(*testtypes.TestProto)
+	ours.MyString2ModelMap["b"].MyString = "ours"
+	ours.MyString2StringMap["a"] = "ours"
+	ours.MySingle.MyString = "ours"
+	theirs.MyString = "theirs"
+	theirs.MyString2StringMap["a"] = "theirs"
+	theirs.MyString2ModelMap["b"].MyInt64 = 9
+	theirs.MySingle.MyString = "theirs"
+	return base, ours, theirs
+}
+
+func TestMergeOurs(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	base, ours, theirs := newMergeModels()
+	baseCopy := cloning.NewCloner().Clone(base).(*testtypes.TestProto)
+
+	merger := updating.NewMerger(res, updating.MergeOurs)
+	merged, conflicts, err := merger.Merge(base, ours, theirs)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(conflicts) != 2 {
+		log.Fail(t, "Expected 2 conflicts but got ", len(conflicts))
+		return
+	}
+	result := merged.(*testtypes.TestProto)
+	if result.MyString2ModelMap["b"].MyString != "ours" || result.MyString != "theirs" ||
+		result.MyString2ModelMap["b"].MyInt64 != 9 {
+		log.Fail(t, "Expected non overlapping changes from both sides")
+		return
+	}
+	if result.MyString2StringMap["a"] != "ours" || result.MySingle.MyString != "ours" {
+		log.Fail(t, "Expected conflicts to be resolved with ours")
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(base, baseCopy) {
+		log.Fail(t, "Expected base to be untouched")
+		return
+	}
+}
+
+func TestMergeTheirsAndCallback(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	base, ours, theirs := newMergeModels()
+	merger := updating.NewMerger(res, updating.MergeTheirs)
+	merged, _, err := merger.Merge(base, ours, theirs)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	result := merged.(*testtypes.TestProto)
+	if result.MyString2StringMap["a"] != "theirs" || result.MySingle.MyString != "theirs" {
+		log.Fail(t, "Expected conflicts to be resolved with theirs")
+		return
+	}
+
+	merger.SetResolver(func(conflict *updating.MergeConflict) updating.MergePolicy {
+		if conflict.PropertyId == "testproto.mystring2stringmap<{24}a>" {
+			return updating.MergeTheirs
+		}
+		return updating.MergeOurs
+	})
+	merged, _, err = merger.Merge(base, ours, theirs)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	result = merged.(*testtypes.TestProto)
+	if result.MyString2StringMap["a"] != "theirs" || result.MySingle.MyString != "ours" {
+		log.Fail(t, "Expected conflicts to be resolved by the callback")
+		return
+	}
+}
+
+// TestMergeSameChanges uses RevertModel, as TestProto has no slice fields.
+func TestMergeSameChanges(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	base := newRevertModel()
+	ours := cloning.NewCloner().Clone(base).(*RevertModel)
+	theirs := cloning.NewCloner().Clone(base).(*RevertModel)
+	//both sides truncate the slice, a group of several removals each
+	ours.Names = ours.Names[:1]
+	theirs.Names = theirs.Names[:1]
+	ours.Tags["a"] = "same"
+	theirs.Tags["a"] = "same"
+
+	merger := updating.NewMerger(res, updating.MergeOurs)
+	merged, conflicts, err := merger.Merge(base, ours, theirs)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(conflicts) != 0 {
+		log.Fail(t, "Expected no conflicts for the same changes but got ", len(conflicts))
+		return
+	}
+	result := merged.(*RevertModel)
+	if len(result.Names) != 1 || result.Tags["a"] != "same" {
+		log.Fail(t, "Expected the same changes to be applied once")
+		return
+	}
+
+	//a group with only some of the same removals is a conflict
+	theirs.Names = []string{base.Names[0], base.Names[1]}
+	_, conflicts, err = merger.Merge(base, ours, theirs)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(conflicts) != 1 {
+		log.Fail(t, "Expected 1 conflict but got ", len(conflicts))
+		return
+	}
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/helping"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+)
+
+type TagIface struct {
+	AdminStatus string `protobuf:"bytes,1,opt,name=admin_status,json=adminStatus,proto3" json:"admin_status,omitempty"`
+	Mtu         int32  `json:"mtu_size"`
+}
+
+type TagPort struct {
+	AdminStatus string `json:"admin_status"`
+	Mtu         int32  `json:"mtu_size"`
+}
+
+type TagDevice struct {
+	DeviceName string               `json:"device_name"`
+	Interfaces map[string]*TagIface `protobuf:"bytes,2,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+	Ports      []*TagPort           `json:"port_list"`
+	Label      string               `json:"devicename"`
+}
+
+func TestPropertyIdNames(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&TagDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	device := &TagDevice{DeviceName: "d1",
+		Interfaces: map[string]*TagIface{"eth0": {AdminStatus: "up", Mtu: 1500}},
+		Ports:      []*TagPort{{AdminStatus: "down", Mtu: 9000}}}
+
+	expected := map[string]interface{}{
+		"tagdevice.interfaces<{24}eth0>.adminstatus":         "up",
+		"tagdevice.interfaces<{24}eth0>.admin_status":        "up",
+		"tagdevice.interfaces<{24}eth0>.adminStatus":         "up",
+		"tagdevice.device_name":                              "d1",
+		"tagdevice.port_list<{2}0>.mtu_size":                 int32(9000),
+		"tagdevice.ports<{2}0>.Admin_Status":                 "down",
+		`tagdevice.interfaces<?admin_status=="up">.mtu_size`: nil,
+	}
+	for id, value := range expected {
+		prop, err := properties.PropertyOf(id, res)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		if value == nil {
+			filtered, err := prop.Get(device)
+			matches, _ := filtered.([]*properties.Match)
+			if err != nil || len(matches) != 1 || matches[0].Value != int32(1500) {
+				log.Fail(t, "Expected the mtu of eth0 for ", id, " got ", matches, err)
+				return
+			}
+			continue
+		}
+		v, err := prop.Get(device)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		if v != value {
+			log.Fail(t, "Expected ", value, " for ", id, " got ", v)
+			return
+		}
+	}
+
+	prop, err := properties.PropertyOf("tagdevice.interfaces<{24}eth0>.admin_status", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	for style, id := range map[properties.NameStyle]string{
+		properties.GoNames:    "tagdevice.interfaces<{24}eth0>.adminstatus",
+		properties.JsonNames:  "tagdevice.interfaces<{24}eth0>.adminStatus",
+		properties.ProtoNames: "tagdevice.interfaces<{24}eth0>.admin_status"} {
+		styled, _ := prop.PropertyIdAs(style)
+		if styled != id {
+			log.Fail(t, "Expected ", id, " got ", styled)
+			return
+		}
+	}
+	//the ports node is a clone of the interfaces node, with its own names
+	prop, err = properties.PropertyOf("tagdevice.ports<{2}0>.mtu", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	styled, _ := prop.PropertyIdAs(properties.JsonNames)
+	if styled != "tagdevice.port_list<{2}0>.mtu_size" {
+		log.Fail(t, "Expected json names of the ports got ", styled)
+		return
+	}
+	_, err = properties.PropertyOf("tagdevice.interfaces<{24}eth0>.admin-status", res)
+	if err == nil {
+		log.Fail(t, "Expected an error for an invalid name")
+		return
+	}
+	_, err = properties.PropertyOf("tagdevice.interfaces<{24}eth0>.status", res)
+	if err == nil {
+		log.Fail(t, "Expected an error for an unknown name")
+		return
+	}
+}
+
+func TestJsonPointerNames(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&TagDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	old := &TagDevice{DeviceName: "d1",
+		Interfaces: map[string]*TagIface{"eth0": {AdminStatus: "up", Mtu: 1500}},
+		Ports:      []*TagPort{{AdminStatus: "down", Mtu: 9000}}}
+	new := cloning.NewCloner().Clone(old).(*TagDevice)
+	new.Interfaces["eth0"].AdminStatus = "down"
+	new.Ports[0].Mtu = 1500
+	upd := updating.NewUpdater(res, false, false)
+	err = upd.Update(old, new)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	patch, err := updating.ToJsonPatch(upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	for _, path := range []string{`"/interfaces/eth0/adminStatus"`, `"/port_list/0/mtu_size"`} {
+		if !strings.Contains(string(patch), path) {
+			log.Fail(t, "Expected the json names in the pointer ", path, " of ", string(patch))
+			return
+		}
+	}
+	patched := cloning.NewCloner().Clone(old).(*TagDevice)
+	_, err = updating.ApplyJsonPatch(patched, patch, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(patched, new) {
+		log.Fail(t, "Expected the patched instance to equal the updated one")
+		return
+	}
+}
+
+func TestAttributeByNameOrder(t *testing.T) {
+	res := newResources()
+	node, err := res.Introspector().Inspect(&TagDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	//devicename is the lowercased DeviceName field and the json name of Label, the field name comes first
+	for i := 0; i < 10; i++ {
+		attr := helping.AttributeByName(node, "devicename")
+		if attr == nil || attr.FieldName != "DeviceName" {
+			log.Fail(t, "Expected the DeviceName field for devicename")
+			return
+		}
+	}
+	attr := helping.AttributeByName(node, "DEVICE_NAME")
+	if attr == nil || attr.FieldName != "DeviceName" {
+		log.Fail(t, "Expected the json name to match ignoring case")
+		return
+	}
+}
+
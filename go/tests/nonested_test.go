@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+func TestNoNestedInspection(t *testing.T) {
+	res := newResources()
+	in := res.Introspector().(*introspecting.Introspector)
+	_, err := in.Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	node, ok := in.Node("testproto.mysingle")
+	if !ok {
+		log.Fail(t, "Could not fetch single node")
+		return
+	}
+	in.AddNoNestedInspection(node, true)
+
+	_, ok = in.Node("testproto.mysingle.mystring")
+	if ok {
+		log.Fail(t, "Expected nested node to be removed")
+		return
+	}
+
+	aside := utils.CreateTestModelInstance(1)
+	aside.MySingle = &testtypes.TestProtoSub{MyString: "a", MyInt64: 1}
+	zside := in.Clone(aside).(*testtypes.TestProto)
+	zside.MySingle = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	upd := updating.NewUpdater(res, false, false)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(upd.Changes()) != 1 {
+		log.Fail(t, "Expected 1 change but got ", len(upd.Changes()))
+		return
+	}
+	if upd.Changes()[0].PropertyId() != "testproto.mysingle" {
+		log.Fail(t, "Unexpected property id ", upd.Changes()[0].PropertyId())
+		return
+	}
+	if aside.MySingle.MyString != "b" || aside.MySingle.MyInt64 != 2 {
+		log.Fail(t, "Expected single to be replaced")
+		return
+	}
+
+	clone := in.Clone(aside).(*testtypes.TestProto)
+	if clone.MySingle != aside.MySingle {
+		log.Fail(t, "Expected single to be shared by the clone")
+		return
+	}
+
+	//only the no nested node path is shared, a map value of the same type is deep copied
+	aside.MyString2ModelMap["c"] = &testtypes.TestProtoSub{MyString: "c", MyInt64: 3}
+	clone = in.Clone(aside).(*testtypes.TestProto)
+	if clone.MyString2ModelMap["c"] == aside.MyString2ModelMap["c"] || clone.MyString2ModelMap["c"].MyString != "c" {
+		log.Fail(t, "Expected the map value to be deep copied by the clone")
+		return
+	}
+}
+
+type NoNestedHolder struct {
+	Name string
+	Sub  *testtypes.TestProtoSub
+}
+
+func TestNoNestedDecorator(t *testing.T) {
+	res := newResources()
+	in := res.Introspector().(*introspecting.Introspector)
+	_, err := in.Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	single, _ := in.Node("testproto.mysingle")
+	introspecting.AddNoNestedInspection(single)
+	_, ok := in.Node("testproto.mysingle.mystring")
+	if ok {
+		log.Fail(t, "Expected the decorator to hide the nested nodes")
+		return
+	}
+	for _, node := range in.Nodes(false, false) {
+		if node.Parent == single {
+			log.Fail(t, "Expected the nested nodes not to be listed")
+			return
+		}
+	}
+	_, ok = in.Node("testproto.mystring2modelmap.mystring")
+	if !ok {
+		log.Fail(t, "Expected another field of the same type to keep its nested nodes")
+		return
+	}
+
+	//the type node is opaque, a field of that type in another struct is still inspected
+	sub, _ := in.NodeByTypeName("TestProtoSub")
+	introspecting.AddNoNestedInspection(sub)
+	_, err = in.Inspect(&NoNestedHolder{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	_, ok = in.Node("nonestedholder.sub.mystring")
+	if !ok {
+		log.Fail(t, "Expected the opacity to be scoped to the decorated field")
+		return
+	}
+}
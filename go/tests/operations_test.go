@@ -0,0 +1,351 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+)
+
+type OpPort struct {
+	Name  string
+	Vlans []int32
+}
+
+type OpCard struct {
+	Name  string
+	Ports []*OpPort
+	Tags  map[string]string
+}
+
+type OpDevice struct {
+	Id    string
+	Cards map[string]*OpCard
+	Names []string
+}
+
+func newOpResources(t *testing.T) ifs.IResources {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&OpDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return nil
+	}
+	node, ok := res.Introspector().Node("opdevice.cards.ports")
+	if !ok {
+		log.Fail(t, "Expected a node for opdevice.cards.ports")
+		return nil
+	}
+	introspecting.AddPrimaryKeyDecorator(node, "Name")
+	return res
+}
+
+func newOpDevice() *OpDevice {
+	return &OpDevice{Id: "d1", Names: []string{"a", "b", "c"},
+		Cards: map[string]*OpCard{
+			"c1": {Name: "c1", Tags: map[string]string{"x": "1", "y": "2"},
+				Ports: []*OpPort{{Name: "p1", Vlans: []int32{10, 20, 30}}, {Name: "p2"}}},
+			"c2": {Name: "c2"}}}
+}
+
+func opProperty(t *testing.T, id string, res ifs.IResources) *properties.Property {
+	prop, err := properties.PropertyOf(id, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return nil
+	}
+	return prop
+}
+
+func TestPropertyDelete(t *testing.T) {
+	res := newOpResources(t)
+	device := newOpDevice()
+	for _, id := range []string{
+		"opdevice.cards<{24}c1>.tags<{24}x>",
+		"opdevice.cards<{24}c1>.ports<{24}p1>.vlans<{2}1>",
+		"opdevice.cards<{24}c1>.ports<{24}p2>",
+		"opdevice.names<{2}0>",
+		"opdevice.cards<{24}c2>.tags<{24}missing>",
+		"opdevice.cards<{24}c9>"} {
+		prop := opProperty(t, id, res)
+		if prop == nil {
+			return
+		}
+		err := prop.Delete(device)
+		if err != nil {
+			log.Fail(t, id, ": ", err.Error())
+			return
+		}
+	}
+	card := device.Cards["c1"]
+	if len(card.Tags) != 1 || card.Tags["y"] != "2" {
+		log.Fail(t, "Expected only the x tag to be deleted ", card.Tags)
+		return
+	}
+	if len(card.Ports) != 1 || len(card.Ports[0].Vlans) != 2 || card.Ports[0].Vlans[1] != 30 {
+		log.Fail(t, "Expected p2 and the vlan 20 to be deleted")
+		return
+	}
+	if len(device.Names) != 2 || device.Names[0] != "b" || len(device.Cards) != 2 || device.Cards["c2"].Tags != nil {
+		log.Fail(t, "Unexpected device after delete ", device.Names, device.Cards)
+		return
+	}
+	for _, id := range []string{"opdevice.names<{2}5>", "opdevice.id", "opdevice.cards"} {
+		prop := opProperty(t, id, res)
+		if prop == nil {
+			return
+		}
+		if prop.Delete(device) == nil {
+			log.Fail(t, "Expected an error deleting ", id)
+			return
+		}
+	}
+}
+
+func TestPropertyInsertAndAppend(t *testing.T) {
+	res := newOpResources(t)
+	device := newOpDevice()
+	names := opProperty(t, "opdevice.names", res)
+	vlans := opProperty(t, "opdevice.cards<{24}c1>.ports<{24}p1>.vlans", res)
+	ports := opProperty(t, "opdevice.cards<{24}c2>.ports", res)
+	if names == nil || vlans == nil || ports == nil {
+		return
+	}
+	if err := names.Insert(device, 1, "x"); err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if err := names.Append(device, "z"); err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if err := vlans.Insert(device, 0, int32(5)); err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if err := ports.Append(device, &OpPort{Name: "p9"}); err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if err := ports.Insert(device, 0, &OpPort{Name: "p8"}); err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(device.Names) != 5 || device.Names[1] != "x" || device.Names[2] != "b" || device.Names[4] != "z" {
+		log.Fail(t, "Unexpected names ", device.Names)
+		return
+	}
+	if v := device.Cards["c1"].Ports[0].Vlans; len(v) != 4 || v[0] != 5 || v[1] != 10 {
+		log.Fail(t, "Unexpected vlans ", v)
+		return
+	}
+	if p := device.Cards["c2"].Ports; len(p) != 2 || p[0].Name != "p8" || p[1].Name != "p9" {
+		log.Fail(t, "Unexpected ports of c2")
+		return
+	}
+	if ports.Append(device, &OpPort{Name: "p9"}) == nil {
+		log.Fail(t, "Expected an error appending a duplicate primary key")
+		return
+	}
+	if names.Insert(device, 9, "y") == nil {
+		log.Fail(t, "Expected an error inserting out of range")
+		return
+	}
+	cards := opProperty(t, "opdevice.cards", res)
+	if cards == nil || cards.Append(device, &OpCard{}) == nil {
+		log.Fail(t, "Expected an error appending to a map")
+		return
+	}
+}
+
+func TestUpdaterOperations(t *testing.T) {
+	res := newOpResources(t)
+	old := newOpDevice()
+	original := cloning.NewCloner().Clone(old).(*OpDevice)
+	new := cloning.NewCloner().Clone(old).(*OpDevice)
+	new.Names = new.Names[:1]
+	delete(new.Cards["c1"].Tags, "y")
+	new.Cards["c1"].Ports = append(new.Cards["c1"].Ports[1:], &OpPort{Name: "p3"})
+
+	upd := updating.NewUpdater(res, false, true)
+	err := upd.Update(old, new)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	operations := map[string]updating.Operation{}
+	for _, change := range upd.Changes() {
+		//the deletions are ifs.Deleted_Entry on the wire, the operation tells them apart
+		if change.Operation() == updating.OperationDelete && change.NewValue() != ifs.Deleted_Entry {
+			log.Fail(t, "Expected Deleted_Entry for ", change.PropertyId(), " got ", change.NewValue())
+			return
+		}
+		operations[change.PropertyId()] = change.Operation()
+	}
+	expected := map[string]updating.Operation{
+		"opdevice.names<{2}2>":                 updating.OperationDelete,
+		"opdevice.names<{2}1>":                 updating.OperationDelete,
+		"opdevice.cards<{24}c1>.tags<{24}y>":   updating.OperationDelete,
+		"opdevice.cards<{24}c1>.ports<{24}p1>": updating.OperationDelete,
+		"opdevice.cards<{24}c1>.ports<{24}p3>": updating.OperationAppend,
+	}
+	for id, op := range expected {
+		if operations[id] != op {
+			log.Fail(t, "Expected operation ", op, " for ", id, " got ", operations[id], " in ", operations)
+			return
+		}
+	}
+	if !cloning.NewDeepEqual().Equal(old, new) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+	applied := cloning.NewCloner().Clone(original).(*OpDevice)
+	for _, change := range upd.Changes() {
+		err = change.Apply(applied)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+	}
+	if !cloning.NewDeepEqual().Equal(applied, new) {
+		log.Fail(t, "Expected the applied changes to make both sides equal")
+		return
+	}
+	err = updating.Revert(old, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(old, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
+
+func TestDeletedEntryInput(t *testing.T) {
+	res := newOpResources(t)
+	device := newOpDevice()
+	for _, id := range []string{
+		"opdevice.cards<{24}c1>.tags<{24}x>",
+		"opdevice.cards<{24}c1>.ports<{24}p2>",
+		"opdevice.names<{2}1>"} {
+		prop := opProperty(t, id, res)
+		if prop == nil {
+			return
+		}
+		_, _, err := prop.Set(device, ifs.Deleted_Entry)
+		if err != nil {
+			log.Fail(t, id, ": ", err.Error())
+			return
+		}
+	}
+	card := device.Cards["c1"]
+	if len(card.Tags) != 1 || len(card.Ports) != 1 || card.Ports[0].Name != "p1" {
+		log.Fail(t, "Expected Deleted_Entry to delete the map entry and the keyed slice element")
+		return
+	}
+	if len(device.Names) != 1 || device.Names[0] != "a" {
+		log.Fail(t, "Expected Deleted_Entry to truncate the slice at the index ", device.Names)
+		return
+	}
+}
+
+func TestOperationEncoding(t *testing.T) {
+	for _, value := range []interface{}{properties.EntryDelete{}, properties.SliceRemove{}, properties.MoveTo(3),
+		properties.SliceInsert{Value: "a"}, "a", int32(5)} {
+		encoded := properties.EncodeValue(value)
+		switch encoded.(type) {
+		case string, []interface{}, int32:
+		default:
+			log.Fail(t, "Expected a serializable encoding of ", value)
+			return
+		}
+		if !cloning.NewDeepEqual().Equal(properties.DecodeValue(encoded), value) {
+			log.Fail(t, "Expected ", value, " to round trip, got ", properties.DecodeValue(encoded))
+			return
+		}
+	}
+	if properties.EncodeValue(properties.EntryDelete{}) != ifs.Deleted_Entry {
+		log.Fail(t, "Expected EntryDelete to be encoded as Deleted_Entry")
+		return
+	}
+}
+
+// TestOperationWireRoundTrip sends the new values of the Updater changes through l8srlz, as a replica does,
+// so the map deletions and the slice truncations must be values l8srlz serializes.
+func TestOperationWireRoundTrip(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	old := newRevertModel()
+	new := newRevertModel()
+	replica := newRevertModel()
+	delete(new.Tags, "a")
+	delete(new.Subs, "b")
+	new.Items = new.Items[:1]
+	new.Names = new.Names[:2]
+
+	upd := updating.NewUpdater(res, false, true)
+	err = upd.Update(old, new)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(upd.Changes()) != 5 {
+		log.Fail(t, "Expected 5 deletions, got ", len(upd.Changes()))
+		return
+	}
+	for _, change := range upd.Changes() {
+		if change.Operation() != updating.OperationDelete {
+			log.Fail(t, "Expected ", change.PropertyId(), " to be a delete operation")
+			return
+		}
+		obj := object.NewEncode()
+		obj.Add(change.NewValue())
+		obj = object.NewDecode(obj.Data(), 0, res.Registry())
+		value, err := obj.Get()
+		if err != nil {
+			log.Fail(t, "Expected the value of ", change.PropertyId(), " to serialize: ", err.Error())
+			return
+		}
+		prop, err := properties.PropertyOf(change.PropertyId(), res)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		_, _, err = prop.Set(replica, value)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+	}
+	if !cloning.NewDeepEqual().Equal(replica, new) {
+		log.Fail(t, "Expected the replica to have the deletions and the truncations")
+		return
+	}
+
+	//a failing batch inserts back the elements the truncations removed
+	tags, err := properties.PropertyOf("revertmodel.tags", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	batch := append(upd.Changes(), updating.NewChange(nil, "not a map", tags))
+	rolledBack := newRevertModel()
+	err = updating.ApplyBatch(rolledBack, batch, false)
+	if err == nil {
+		log.Fail(t, "Expected the batch to fail")
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(rolledBack, newRevertModel()) {
+		log.Fail(t, "Expected the rollback to restore the truncated slices")
+		return
+	}
+}
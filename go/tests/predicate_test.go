@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+type PredIface struct {
+	Name        string
+	AdminStatus string
+	Mtu         int32
+	Enabled     bool
+	OperStatus  PredStatus
+}
+
+type PredStatus int32
+
+func (this PredStatus) String() string {
+	if this == 1 {
+		return "Up"
+	}
+	return "Down"
+}
+
+type PredDevice struct {
+	Id         string
+	Interfaces map[string]*PredIface
+	Ports      []*PredIface
+}
+
+func newPredDevice() *PredDevice {
+	return &PredDevice{Id: "d1",
+		Interfaces: map[string]*PredIface{
+			"eth0": {Name: "eth0", AdminStatus: "up", Mtu: 1500, Enabled: true},
+			"eth1": {Name: "eth1", AdminStatus: "down", Mtu: 9000, Enabled: true},
+			"eth2": {Name: "eth2", AdminStatus: "up", Mtu: 9000, Enabled: false}},
+		Ports: []*PredIface{{Name: "p0", AdminStatus: "up", Mtu: 1500}, {Name: "p1", AdminStatus: "testing", Mtu: 1400}}}
+}
+
+func predicateNames(t *testing.T, propertyId string, expected ...interface{}) bool {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&PredDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	prop, err := properties.PropertyOf(propertyId, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	value, err := prop.Get(newPredDevice())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	matches, ok := value.([]*properties.Match)
+	if !ok || len(matches) != len(expected) {
+		log.Fail(t, "Expected ", len(expected), " values for ", propertyId, " got ", value)
+		return false
+	}
+	for i, m := range matches {
+		if m.Value != expected[i] {
+			log.Fail(t, "Expected ", expected[i], " for ", propertyId, " got ", m.PropertyId, "=", m.Value)
+			return false
+		}
+		//the property id of a filtered value is its concrete id, getting it returns the value itself
+		keyed, err := properties.PropertyOf(m.PropertyId, prop.Resources())
+		if err != nil {
+			log.Fail(t, err.Error())
+			return false
+		}
+		v, err := keyed.Get(newPredDevice())
+		if err != nil || v != expected[i] {
+			log.Fail(t, "Expected ", expected[i], " for ", m.PropertyId, " got ", v, err)
+			return false
+		}
+	}
+	return true
+}
+
+func TestPropertyIdPredicates(t *testing.T) {
+	if !predicateNames(t, `preddevice.interfaces<?adminstatus=="up">.name`, "eth0", "eth2") {
+		return
+	}
+	if !predicateNames(t, `preddevice.interfaces<?adminstatus=="up" && mtu>=9000>.name`, "eth2") {
+		return
+	}
+	if !predicateNames(t, `preddevice.interfaces<?adminstatus=="down" || !enabled==true>.name`, "eth1", "eth2") {
+		return
+	}
+	if !predicateNames(t, `preddevice.interfaces<?name in ("eth1", "eth2", "eth9") && (mtu<1500 || enabled==false)>.name`, "eth2") {
+		return
+	}
+	if !predicateNames(t, `preddevice.ports<?mtu in (1400, 9000)>.name`, "p1") {
+		return
+	}
+	if !predicateNames(t, `preddevice.ports<?adminstatus!="up">.mtu`, int32(1400)) {
+		return
+	}
+}
+
+func TestPropertyIdPredicateErrors(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&PredDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	for _, id := range []string{
+		`preddevice.interfaces<?mtu=="big">.name`,
+		`preddevice.interfaces<?speed==1>.name`,
+		`preddevice.interfaces<?mtu=>1>.name`,
+		`preddevice.interfaces<?(mtu==1>.name`,
+		`preddevice.interfaces<?name=="eth0>.name`,
+		`preddevice.interfaces<?operstatus=="Sideways">.name`} {
+		_, err = properties.PropertyOf(id, res)
+		if err == nil {
+			log.Fail(t, "Expected an error for ", id)
+			return
+		}
+	}
+}
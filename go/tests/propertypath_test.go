@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+func TestPropertyPathRoundTrip(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&WildDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	for _, id := range []string{
+		"wilddevice.id",
+		"wilddevice.ifaces",
+		"wilddevice.ifaces<{24}eth0>.status",
+		"wilddevice.ports<{2}1>.name",
+		"wilddevice.ifaces<*>.name",
+		"wilddevice.ifaces<{24}eth0|{24}eth1>.name",
+		`wilddevice.ifaces<?status>=2 && name!="a.b>">.name`,
+		`wilddevice.ifaces<{24}Gi0/0/0.100>.name`,
+		`wilddevice.ifaces<{24}a\<b\>\|c\\d>.name`} {
+		path, err := properties.ParsePropertyPath(id)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		if path.String() != id {
+			log.Fail(t, "Expected path ", id, " got ", path.String())
+			return
+		}
+		prop, err := properties.PropertyOf(id, res)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		pid, _ := prop.PropertyId()
+		if pid != id {
+			log.Fail(t, "Expected property id ", id, " got ", pid)
+			return
+		}
+	}
+}
+
+func TestPropertyPathEscapedKeys(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&WildDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	device := newWildDevice()
+	device.Ifaces["Gi0/0/0.100"] = &WildIface{Name: "sub", Status: 7}
+	device.Ifaces["a<b>|c"] = &WildIface{Name: "odd", Status: 8}
+	path, err := properties.ParsePropertyPath(`wilddevice.ifaces<{24}a\<b\>\|c>.status`)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if len(path.Segments) != 3 || path.Segments[1].Key == nil || path.Segments[1].Key.Keys[0] != "{24}a<b>|c" {
+		log.Fail(t, "Unexpected segments of ", path.String())
+		return
+	}
+	for id, expected := range map[string]int32{
+		`wilddevice.ifaces<{24}Gi0/0/0.100>.status`: 7,
+		`wilddevice.ifaces<{24}a\<b\>\|c>.status`:   8} {
+		prop, err := properties.PropertyOf(id, res)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		value, err := prop.Get(device)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		if value != expected {
+			log.Fail(t, "Expected ", expected, " for ", id, " got ", value)
+			return
+		}
+	}
+	matches, err := properties.PropertyOf("wilddevice.ifaces<*>.status", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	found, _ := matches.Matches(device)
+	for _, m := range found {
+		prop, err := properties.PropertyOf(m.PropertyId, res)
+		if err != nil {
+			log.Fail(t, err.Error())
+			return
+		}
+		value, _ := prop.Get(device)
+		if value != m.Value {
+			log.Fail(t, "Match id ", m.PropertyId, " does not round trip")
+			return
+		}
+	}
+}
+
+func TestPropertyPathErrors(t *testing.T) {
+	for id, column := range map[string]int{
+		"wilddevice..ifaces":                   12,
+		"wilddevice.ifaces<{24}eth0":           18,
+		"wilddevice.ifaces<{24}a<b>.name":      24,
+		"wilddevice.ifaces<{24}a||{24}b>.name": 25,
+		"wilddevice.ifaces<{24}a>name":         25,
+		"wilddevice.ifaces.":                   19,
+		"wilddevice.ifaces<?>.name":            19,
+	} {
+		_, err := properties.ParsePropertyPath(id)
+		pathErr := &properties.PathError{}
+		if !errors.As(err, &pathErr) {
+			log.Fail(t, "Expected a path error for ", id, " got ", err)
+			return
+		}
+		if pathErr.Column != column {
+			log.Fail(t, "Expected column ", column, " for ", id, " got ", pathErr.Error())
+			return
+		}
+	}
+	res := newResources()
+	_, err := res.Introspector().Inspect(&WildDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	_, err = properties.PropertyOf(`wilddevice.ifaces<?status==1 && speed>2>.name`, res)
+	pathErr := &properties.PathError{}
+	if !errors.As(err, &pathErr) || pathErr.Column != 33 {
+		log.Fail(t, "Expected a predicate error at column 33, got ", err)
+		return
+	}
+}
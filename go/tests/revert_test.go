@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8types/go/testtypes"
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+	"github.com/saichler/l8reflect/go/tests/utils"
+)
+
+type RevertSub struct {
+	Name  string
+	Value int32
+}
+
+// RevertModel has Single before Subs and Items, the nodes of the other fields of RevertSub are
+// cloned from the node of its first field, which must not be a map or a slice.
+type RevertModel struct {
+	Name   string
+	Count  int32
+	Single *RevertSub
+	Tags   map[string]string
+	Subs   map[string]*RevertSub
+	Items  []*RevertSub
+	Names  []string
+}
+
+func newRevertModel() *RevertModel {
+	return &RevertModel{
+		Name:   "model",
+		Count:  1,
+		Tags:   map[string]string{"a": "1", "b": "2"},
+		Subs:   map[string]*RevertSub{"a": {Name: "a", Value: 1}, "b": {Name: "b", Value: 2}},
+		Items:  []*RevertSub{{Name: "i0"}, {Name: "i1"}, {Name: "i2"}},
+		Names:  []string{"n0", "n1", "n2"},
+		Single: &RevertSub{Name: "single", Value: 3},
+	}
+}
+
+func TestRevert(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&testtypes.TestProto{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := utils.CreateTestModelInstance(1)
+	aside.MyString2StringMap["a"] = "1"
+	aside.MyString2StringMap["b"] = "2"
+	aside.MyString2ModelMap["a"] = &testtypes.TestProtoSub{MyString: "a", MyInt64: 1}
+	aside.MyString2ModelMap["b"] = &testtypes.TestProtoSub{MyString: "b", MyInt64: 2}
+	original := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside := cloning.NewCloner().Clone(aside).(*testtypes.TestProto)
+	zside.MyString = "reverted"
+	zside.MyEnum = testtypes.TestEnum_ValueTwo
+	delete(zside.MyString2StringMap, "a")
+	zside.MyString2StringMap["b"] = "3"
+	zside.MyString2StringMap["c"] = "4"
+	delete(zside.MyString2ModelMap, "a")
+	zside.MyString2ModelMap["b"].MyInt64 = 7
+	zside.MySingle = nil
+
+	upd := updating.NewUpdater(res, true, true)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	deq := cloning.NewDeepEqual()
+	if !deq.Equal(aside, zside) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+
+	err = updating.Revert(aside, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !deq.Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
+
+// TestRevertSlices uses RevertModel, as TestProto has no slice fields.
+func TestRevertSlices(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newRevertModel()
+	original := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside.Items = zside.Items[:1]
+	zside.Names = append(zside.Names[:1], "x")
+
+	upd := updating.NewUpdater(res, true, true)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	err = updating.Revert(aside, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original slices")
+		return
+	}
+}
+
+// TestRevertAppend uses RevertModel, as TestProto has no slice fields.
+func TestRevertAppend(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newRevertModel()
+	aside.Single = nil
+	original := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside.Items = append(zside.Items, &RevertSub{Name: "i3"})
+	zside.Names = append(zside.Names, "n3", "n4")
+	zside.Subs["c"] = &RevertSub{Name: "c"}
+	zside.Single = &RevertSub{Name: "single"}
+
+	upd := updating.NewUpdater(res, false, false)
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+
+	err = updating.Revert(aside, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !cloning.NewDeepEqual().Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8reflect/go/reflect/updating"
+)
+
+func TestSliceEditScript(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&RevertModel{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	aside := newRevertModel()
+	aside.Names = []string{"a", "b", "c", "d", "e"}
+	original := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside := cloning.NewCloner().Clone(aside).(*RevertModel)
+	zside.Names = []string{"a", "c", "x", "d", "e", "f"}
+	zside.Items = []*RevertSub{zside.Items[0], zside.Items[2]}
+
+	upd := updating.NewUpdater(res, true, true)
+	upd.SetSliceEditScript(true)
+	changes, err := upd.Diff(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	expected := map[string]interface{}{
+		"revertmodel.names<{2}1>": properties.SliceRemove{},
+		"revertmodel.names<{2}2>": properties.SliceInsert{Value: "x"},
+		"revertmodel.names<{2}5>": properties.SliceInsert{Value: "f"},
+		"revertmodel.items<{2}1>": properties.SliceRemove{},
+	}
+	if len(changes) != len(expected) {
+		log.Fail(t, "Expected ", len(expected), " changes but got ", len(changes))
+		return
+	}
+	for _, change := range changes {
+		value, ok := expected[change.PropertyId()]
+		if !ok || value != change.NewValue() {
+			log.Fail(t, "Unexpected change ", change.PropertyId())
+			return
+		}
+	}
+
+	target := cloning.NewCloner().Clone(aside).(*RevertModel)
+	err = updating.ApplyBatch(target, changes, true)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	deq := cloning.NewDeepEqual()
+	if !deq.Equal(target, zside) {
+		log.Fail(t, "Expected the edit script to turn the old slices into the new ones")
+		return
+	}
+
+	err = upd.Update(aside, zside)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !deq.Equal(aside, zside) {
+		log.Fail(t, "Expected update to make both sides equal")
+		return
+	}
+	err = updating.Revert(aside, upd.Changes())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	if !deq.Equal(aside, original) {
+		log.Fail(t, "Expected revert to restore the original")
+		return
+	}
+}
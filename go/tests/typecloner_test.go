@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saichler/l8reflect/go/reflect/cloning"
+)
+
+type ClonedState struct {
+	Value string
+	Calls int32
+}
+
+type TypeClonerModel struct {
+	Name    string
+	Created time.Time
+	Timeout time.Duration
+	Total   *big.Int
+	Lock    sync.Mutex
+	State   *ClonedState
+}
+
+func TestBuiltinTypeCloners(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("test", 3600))
+	model := &TypeClonerModel{Name: "model", Created: created, Timeout: time.Second, Total: big.NewInt(42)}
+	model.Lock.Lock()
+	defer model.Lock.Unlock()
+
+	clone := cloning.NewCloner().Clone(model).(*TypeClonerModel)
+	if !clone.Created.Equal(created) || clone.Created.Location() != created.Location() {
+		log.Fail(t, "Expected time.Time to be copied, got ", clone.Created)
+		return
+	}
+	if clone.Timeout != time.Second {
+		log.Fail(t, "Expected time.Duration to be copied")
+		return
+	}
+	if clone.Total == model.Total || clone.Total.Cmp(model.Total) != 0 {
+		log.Fail(t, "Expected *big.Int to be deep copied")
+		return
+	}
+	if !clone.Lock.TryLock() {
+		log.Fail(t, "Expected the clone to get a new unlocked mutex")
+		return
+	}
+	clone.Lock.Unlock()
+}
+
+func TestCustomTypeCloner(t *testing.T) {
+	cloner := cloning.NewCloner()
+	cloner.AddTypeCloner(reflect.TypeOf(ClonedState{}), func(value reflect.Value, cloner *cloning.Cloner) reflect.Value {
+		state := value.Interface().(ClonedState)
+		return reflect.ValueOf(ClonedState{Value: state.Value})
+	})
+	model := &TypeClonerModel{Name: "model", State: &ClonedState{Value: "state", Calls: 5}}
+	clone := cloner.Clone(model).(*TypeClonerModel)
+	if clone.State == model.State || clone.State.Value != "state" || clone.State.Calls != 0 {
+		log.Fail(t, "Expected the custom type cloner to take precedence over the struct cloner")
+		return
+	}
+}
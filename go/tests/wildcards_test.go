@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8reflect/go/reflect/properties"
+)
+
+type WildIface struct {
+	Name   string
+	Status int32
+}
+
+type WildDevice struct {
+	Id     string
+	Ifaces map[string]*WildIface
+	Ports  []*WildIface
+	Vlans  map[int32]*WildIface
+}
+
+func newWildDevice() *WildDevice {
+	return &WildDevice{Id: "d1",
+		Ifaces: map[string]*WildIface{"eth0": {Name: "eth0", Status: 1}, "eth1": {Name: "eth1", Status: 2}, "eth2": {Name: "eth2", Status: 3}},
+		Ports:  []*WildIface{{Name: "p0", Status: 4}, {Name: "p1", Status: 5}}}
+}
+
+func wildMatches(t *testing.T, propertyId string, expected map[string]interface{}) bool {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&WildDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	prop, err := properties.PropertyOf(propertyId, res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	value, err := prop.Get(newWildDevice())
+	if err != nil {
+		log.Fail(t, err.Error())
+		return false
+	}
+	matches, ok := value.([]*properties.Match)
+	if !ok || len(matches) != len(expected) {
+		log.Fail(t, "Expected ", len(expected), " matches for ", propertyId, " got ", value)
+		return false
+	}
+	for _, m := range matches {
+		if expected[m.PropertyId] != m.Value {
+			log.Fail(t, "Unexpected match ", m.PropertyId, "=", m.Value, " for ", propertyId)
+			return false
+		}
+	}
+	return true
+}
+
+func TestPropertyIdWildcards(t *testing.T) {
+	if !wildMatches(t, "wilddevice.ifaces<*>.status", map[string]interface{}{
+		"wilddevice.ifaces<{24}eth0>.status": int32(1),
+		"wilddevice.ifaces<{24}eth1>.status": int32(2),
+		"wilddevice.ifaces<{24}eth2>.status": int32(3)}) {
+		return
+	}
+	if !wildMatches(t, "wilddevice.ifaces<{24}eth0|{24}eth2|{24}eth9>.status", map[string]interface{}{
+		"wilddevice.ifaces<{24}eth0>.status": int32(1),
+		"wilddevice.ifaces<{24}eth2>.status": int32(3)}) {
+		return
+	}
+	if !wildMatches(t, "wilddevice.ports<*>.name", map[string]interface{}{
+		"wilddevice.ports<{2}0>.name": "p0",
+		"wilddevice.ports<{2}1>.name": "p1"}) {
+		return
+	}
+}
+
+func TestPropertyIdWildcardSet(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&WildDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	prop, err := properties.PropertyOf("wilddevice.ifaces<*>.status", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	//a fully keyed property id is not a pattern, Get returns its value as before
+	keyed, err := properties.PropertyOf("wilddevice.ports<{2}1>.name", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	name, err := keyed.Get(newWildDevice())
+	if err != nil || name != "p1" {
+		log.Fail(t, "Expected the keyed property value, got ", name)
+		return
+	}
+	values := prop.GetAsValues(newWildDevice())
+	if len(values) != 3 || values[0].Interface() != int32(1) || values[2].Interface() != int32(3) {
+		log.Fail(t, "Expected the matched values, got ", values)
+		return
+	}
+	_, _, err = prop.Set(newWildDevice(), int32(5))
+	if err == nil {
+		log.Fail(t, "Expected an error setting a wildcard property id")
+		return
+	}
+	id, _ := prop.PropertyId()
+	if id != "wilddevice.ifaces<*>.status" {
+		log.Fail(t, "Expected the property id to keep the wildcard, got ", id)
+		return
+	}
+}
+
+func TestPropertyIdWildcardKeyOrder(t *testing.T) {
+	res := newResources()
+	_, err := res.Introspector().Inspect(&WildDevice{})
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	prop, err := properties.PropertyOf("wilddevice.vlans<*>.name", res)
+	if err != nil {
+		log.Fail(t, err.Error())
+		return
+	}
+	device := &WildDevice{Vlans: map[int32]*WildIface{10: {Name: "v10"}, 2: {Name: "v2"}, -1: {Name: "v-1"}}}
+	value, err := prop.Get(device)
+	matches, ok := value.([]*properties.Match)
+	if err != nil || !ok || len(matches) != 3 {
+		log.Fail(t, "Expected 3 matches ", err)
+		return
+	}
+	for i, name := range []string{"v-1", "v2", "v10"} {
+		if matches[i].Value != name {
+			log.Fail(t, "Expected the matches sorted by the numeric keys, got ", matches[i].PropertyId, " at ", i)
+			return
+		}
+	}
+}